@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// A goal that is unlocked once reached and kept from then on.
+type achievement struct {
+	// The key the achievement is saved under. This must not change.
+	id          string
+	name        string
+	description string
+	// Reports whether the achievement was reached with the round just played.
+	reached func(result Result) bool
+}
+
+var achievements []achievement
+
+func init() {
+	achievements = []achievement{
+		{"first-perfect", "Flawless", "type a round without any mistakes", func(result Result) bool {
+			return isPerfect(result)
+		}},
+		{"perfect-streak", "On a Roll", fmt.Sprint("type ", perfectStreakGoal, " rounds in a row without any mistakes"), func(result Result) bool {
+			return session.perfectStreak >= perfectStreakGoal
+		}},
+		{"100-wpm", "Century", fmt.Sprintf("type at %d WPM with at least %d%% accuracy", speedGoal, speedGoalAccuracy), func(result Result) bool {
+			return result.wpm >= speedGoal && result.accuracy >= speedGoalAccuracy
+		}},
+		{"every-text", "Completionist", "type every text at least once", func(result Result) bool {
+			for _, entry := range text {
+				if _, ok := scores.Records[entry.text]; !ok {
+					return false
+				}
+			}
+			return true
+		}},
+		{"regular", "Regular", fmt.Sprint("play on ", daysGoal, " different days"), func(result Result) bool {
+			return daysPlayed() >= daysGoal
+		}},
+	}
+}
+
+const (
+	perfectStreakGoal = 10
+	speedGoal         = 100
+	speedGoalAccuracy = 95
+	daysGoal          = 7
+)
+
+// Reports whether the round was typed without any mistakes.
+func isPerfect(result Result) bool {
+	return result.distance == 0 && result.wrongKeys == 0
+}
+
+// Counts the days on which any attempts were made.
+func daysPlayed() int {
+	days := make(map[string]bool)
+	for _, record := range scores.Records {
+		for _, attempt := range record.Attempts {
+			days[attempt.Time.Local().Format("2006-01-02")] = true
+		}
+	}
+	days[now().Format("2006-01-02")] = true
+	return len(days)
+}
+
+// Unlocks the achievements reached with the round just played and announces them.
+// Achievements that were already unlocked are not announced again.
+func unlockAchievements(result Result) {
+	for _, achievement := range achievements {
+		if _, unlocked := scores.Achievements[achievement.id]; unlocked || !achievement.reached(result) {
+			continue
+		}
+
+		if scores.Achievements == nil {
+			scores.Achievements = make(map[string]time.Time)
+		}
+		scores.Achievements[achievement.id] = now()
+		fmt.Printf("Achievement unlocked: %s (%s)\n", achievement.name, achievement.description)
+	}
+}
+
+// Lists all achievements and when those unlocked were unlocked.
+func listAchievements(args []string) (err error) {
+	scores = newScores()
+	if err = scores.Load(); err != nil {
+		return
+	}
+
+	for _, achievement := range achievements {
+		status := "locked"
+		if at, unlocked := scores.Achievements[achievement.id]; unlocked {
+			status = "unlocked " + at.Format("2006-01-02")
+		}
+		fmt.Printf("%-14s %-20s %s\n", achievement.name, status, achievement.description)
+	}
+	printMilestones()
+
+	return
+}
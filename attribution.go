@@ -0,0 +1,30 @@
+package main
+
+// Where a text is from.
+type attribution struct {
+	// Who wrote or said the text, or where it was published.
+	name string
+	// A link to where the text is from. This is empty if there is none.
+	url string
+}
+
+// The tour the built-in sentences about Go are from.
+var goTour = attribution{"A Tour of Go", "https://tour.golang.org/"}
+
+// Reports whether links may be made clickable with OSC 8 escape sequences.
+// This needs -hyperlinks and is disabled wherever colors are.
+func hyperlinksEnabled() bool {
+	return *hyperlinks && colorEnabled()
+}
+
+// Gets the attribution as it is shown: its name, linked to its URL if hyperlinks are enabled and followed by the URL otherwise.
+func (source attribution) String() string {
+	switch {
+	case source.url == "":
+		return source.name
+	case hyperlinksEnabled():
+		return "\x1b]8;;" + source.url + "\x1b\\" + source.name + "\x1b]8;;\x1b\\"
+	default:
+		return source.name + " <" + source.url + ">"
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// Runs rounds without a player and prints what the scoring makes of them.
+// The texts are chosen with a fixed seed and typed either by a simulated player at a fixed speed or as given in a file,
+// so the report is the same every time as long as the scoring doesn't change.
+func runBenchmark(args []string) error {
+	flags := flag.NewFlagSet("benchmark", flag.ContinueOnError)
+	seed := flags.Int64("seed", 1, "the seed for choosing the texts")
+	rounds := flags.Int("rounds", 10, "how many rounds to run")
+	wpm := flags.Float64("wpm", 60, "the speed at which the texts are typed")
+	inputFile := flags.String("input", "", "a file with the input for each round, one per line, instead of typing the texts as simulated")
+	errorRate := flags.Float64("errors", 0, "the chance of each character being typed wrong by the simulated player, from 0 to 1")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *wpm <= 0 {
+		return fmt.Errorf("the speed must be positive")
+	}
+
+	var inputs []string
+	if *inputFile != "" {
+		file, err := os.Open(*inputFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			inputs = append(inputs, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		if len(inputs) < *rounds {
+			return fmt.Errorf("the input file has %d lines but %d rounds are to be run", len(inputs), *rounds)
+		}
+	}
+
+	rng = rand.New(rand.NewSource(*seed))
+	mistakes := rand.New(rand.NewSource(*seed))
+	recentRandInts = nil
+	scores = newScores()
+
+	var totalScore int
+	var totalWPM, totalAccuracy float64
+	for round := 0; round < *rounds; round++ {
+		textToType := nextText().text
+
+		input := simulateInput(textToType, *errorRate, mistakes)
+		if inputs != nil {
+			input = inputs[round]
+		}
+
+		// The time it takes to type the input at the given speed.
+		minutes := float64(len([]rune(input))) / 5 / *wpm
+		totalTime := time.Duration(minutes * float64(time.Minute)).Round(time.Millisecond)
+
+		result := scoreInput(textToType, input, rawInput{}, totalTime)
+
+		fmt.Printf("%3d  %s  time=%-8s distance=%-3d score=%-5d wpm=%-6.1f accuracy=%.1f%%\n",
+			round+1, hashText(textToType), totalTime, result.distance, result.score, result.wpm, result.accuracy)
+
+		totalScore += result.score
+		totalWPM += result.wpm
+		totalAccuracy += result.accuracy
+	}
+
+	if *rounds > 0 {
+		n := float64(*rounds)
+		fmt.Printf("total score=%d  mean wpm=%.1f  mean accuracy=%.1f%%\n", totalScore, totalWPM/n, totalAccuracy/n)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// The typing done since the last break, to remind the player to take one with -break-reminder or -break-rounds.
+var sinceBreak struct {
+	// How long the rounds took.
+	typing time.Duration
+	// How many rounds were played.
+	rounds int
+}
+
+// Reports whether a break is to be suggested at any point.
+func remindsOfBreaks() bool {
+	return *breakReminder > 0 || *breakRounds > 0
+}
+
+// Adds the round to the typing done since the last break and suggests taking one if it is due,
+// with -break-wait waiting until the player is back.
+func remindOfBreak(result Result) {
+	sinceBreak.typing += result.totalTime
+	sinceBreak.rounds++
+
+	due := *breakReminder > 0 && sinceBreak.typing >= *breakReminder ||
+		*breakRounds > 0 && sinceBreak.rounds >= *breakRounds
+	if !due {
+		return
+	}
+
+	fmt.Printf("\nYou typed for %v over %d %s. Time for a break! Rest your hands and eyes for a few minutes.\n",
+		sinceBreak.typing.Round(time.Second), sinceBreak.rounds, pluralize("round", sinceBreak.rounds))
+	sinceBreak.typing = 0
+	sinceBreak.rounds = 0
+
+	if *breakWait {
+		fmt.Println("Press Enter once you are back")
+		startedAt := now()
+		readLine()
+		fmt.Println("Welcome back after", now().Sub(startedAt).Round(time.Second))
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// The characters practiced in numbers mode.
+const digits = "0123456789"
+
+// The characters practiced in symbols mode, as used in programming.
+const symbols = "!@#$%^&*()-_=+[]{};:'\",.<>/?\\|`~"
+
+// How many characters are grouped together in a generated text of numbers or symbols.
+const charGroupLength = 5
+
+// Generates a text of -chars random characters out of the given ones, in groups separated by spaces.
+func generateChars(chars string) string {
+	runes := []rune(chars)
+
+	var generated strings.Builder
+	for i := 0; i < *charCount; i++ {
+		if i > 0 && i%charGroupLength == 0 {
+			generated.WriteRune(' ')
+		}
+		generated.WriteRune(runes[rng.Intn(len(runes))])
+	}
+	return generated.String()
+}
+
+// Prints how many times each character of the text was typed right, starting with the character typed right least often.
+// Whitespace is left out.
+func printCharAccuracy(ops []editOp) {
+	total := make(map[rune]int)
+	right := make(map[rune]int)
+	for _, op := range ops {
+		if op.kind == editInsertion || unicode.IsSpace(op.want) {
+			continue
+		}
+
+		total[op.want]++
+		if op.kind == editMatch {
+			right[op.want]++
+		}
+	}
+
+	var chars []rune
+	for char := range total {
+		chars = append(chars, char)
+	}
+	accuracy := func(char rune) float64 {
+		return float64(right[char]) / float64(total[char]) * 100
+	}
+	sort.Slice(chars, func(i, j int) bool {
+		a, b := accuracy(chars[i]), accuracy(chars[j])
+		if a != b {
+			return a < b
+		}
+		return chars[i] < chars[j]
+	})
+
+	var listed []string
+	for _, char := range chars {
+		listed = append(listed, fmt.Sprintf("%c %.0f%%", char, accuracy(char)))
+	}
+	fmt.Println("Accuracy per character:", strings.Join(listed, ", "))
+}
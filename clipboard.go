@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// Reads the texts to be typed from the clipboard, one text per non-empty line.
+func clipboardTexts() ([]string, error) {
+	content, err := clipboard.ReadAll()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var texts []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			texts = append(texts, line)
+		}
+	}
+
+	if len(texts) == 0 {
+		return nil, errors.New("the clipboard is empty; copy some text first")
+	}
+
+	return texts, nil
+}
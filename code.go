@@ -0,0 +1,159 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// The code snippets typed in code mode unless -texts is given.
+var snippets = []string{
+	"func add(a, b int) int {\n\treturn a + b\n}",
+	"for i := 0; i < 10; i++ {\n\tfmt.Println(i)\n}",
+	"if err != nil {\n\treturn err\n}",
+	"type Point struct {\n\tX, Y int\n}",
+	"// Greets the world.\nfmt.Println(\"Hello, world!\")",
+	"switch x := f(); x {\ncase 0:\n\tbreak\ndefault:\n\tpanic(\"unreachable\")\n}",
+}
+
+// Splits the content of a snippets file into code snippets, which are separated by blank lines.
+// Trailing whitespace is removed from each line because it can't be seen.
+func splitSnippets(content string) []string {
+	var snippets []string
+	var lines []string
+	for _, line := range strings.Split(content+"\n", "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line != "" {
+			lines = append(lines, line)
+			continue
+		}
+
+		if len(lines) > 0 {
+			snippets = append(snippets, strings.Join(lines, "\n"))
+			lines = nil
+		}
+	}
+	return snippets
+}
+
+// How many spaces a tab is shown as unless -tab-width is given.
+const defaultTabWidth = 4
+
+// Replaces the tabs with spaces for display.
+// Each tab is always shown as the same number of spaces so that the input lines up with the text.
+func expandTabs(str string) string {
+	width := *tabWidth
+	if width <= 0 {
+		width = defaultTabWidth
+	}
+	return strings.ReplaceAll(str, "\t", strings.Repeat(" ", width))
+}
+
+// Gets what pressing Tab types in raw mode: a tab, or with -tab-width, that many spaces.
+// This should match how the texts are indented.
+func indentation() string {
+	if *tabWidth > 0 {
+		return strings.Repeat(" ", *tabWidth)
+	}
+	return "\t"
+}
+
+// The kind of token a character of code belongs to.
+type syntaxKind int
+
+const (
+	syntaxPlain syntaxKind = iota
+	syntaxKeyword
+	syntaxString
+	syntaxComment
+	syntaxNumber
+)
+
+// The roles of the -theme colors code is highlighted with.
+var syntaxRoles = map[syntaxKind]colorRole{
+	syntaxKeyword: roleKeyword,
+	syntaxString:  roleString,
+	syntaxComment: roleComment,
+	syntaxNumber:  roleNumber,
+}
+
+// Keywords of common programming languages.
+var keywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "class": true, "const": true, "continue": true, "def": true,
+	"default": true, "defer": true, "else": true, "false": true, "fn": true, "for": true, "func": true,
+	"go": true, "if": true, "import": true, "interface": true, "let": true, "map": true, "nil": true,
+	"null": true, "package": true, "range": true, "return": true, "select": true, "struct": true,
+	"switch": true, "true": true, "type": true, "var": true, "while": true,
+}
+
+// Determines the kind of token each character of a line of code belongs to.
+// This is a rough approximation that works for most C-like languages and Python.
+func highlightLine(line []rune) []syntaxKind {
+	kinds := make([]syntaxKind, len(line))
+	for i := 0; i < len(line); {
+		r := line[i]
+		switch {
+		case r == '#' || (r == '/' && i+1 < len(line) && line[i+1] == '/'):
+			for ; i < len(line); i++ {
+				kinds[i] = syntaxComment
+			}
+		case r == '"' || r == '\'' || r == '`':
+			kinds[i] = syntaxString
+			for i++; i < len(line); i++ {
+				kinds[i] = syntaxString
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+					kinds[i] = syntaxString
+				} else if line[i] == r {
+					i++
+					break
+				}
+			}
+		case unicode.IsDigit(r):
+			for ; i < len(line) && (unicode.IsDigit(line[i]) || unicode.IsLetter(line[i]) || line[i] == '.'); i++ {
+				kinds[i] = syntaxNumber
+			}
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for ; i < len(line) && (unicode.IsLetter(line[i]) || unicode.IsDigit(line[i]) || line[i] == '_'); i++ {
+			}
+			if keywords[string(line[start:i])] {
+				for j := start; j < i; j++ {
+					kinds[j] = syntaxKeyword
+				}
+			}
+		default:
+			i++
+		}
+	}
+	return kinds
+}
+
+// Gets the line of code from the given character on, highlighted if colors are enabled and with its tabs expanded.
+func highlightRest(line string, from int) string {
+	runes := []rune(line)
+	if from >= len(runes) {
+		return ""
+	}
+
+	if !colorEnabled() {
+		return expandTabs(string(runes[from:]))
+	}
+
+	kinds := highlightLine(runes)
+
+	var highlighted strings.Builder
+	start := from
+	for i := from; i < len(runes); i++ {
+		if i+1 < len(runes) && kinds[i+1] == kinds[i] {
+			continue
+		}
+		token := expandTabs(string(runes[start : i+1]))
+		if kinds[i] == syntaxPlain {
+			highlighted.WriteString(token)
+		} else {
+			highlighted.WriteString(paint(syntaxRoles[kinds[i]], token))
+		}
+		start = i + 1
+	}
+	return highlighted.String()
+}
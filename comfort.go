@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// How many of the most recent attempts typed well the comfortable length is estimated from in comfort mode.
+const comfortAttempts = 20
+
+// The least accuracy in percent an attempt needs to count as typed well in comfort mode.
+const comfortAccuracy = 95
+
+// How far the length of a text can be from the comfortable length for it to count as comfortable, as a fraction of it.
+const comfortTolerance = 0.25
+
+// The chance of a longer text being served in comfort mode, to stretch the player a little.
+const stretchChance = 0.2
+
+// How much longer than the comfortable length a text served to stretch the player is at most, as a fraction of it.
+const stretchLimit = 0.75
+
+// Estimates the length of text the player is comfortable with: the median length of the texts of the most recent attempts typed well.
+// This is 0 if no attempt was typed well yet.
+func comfortableLength() int {
+	type typedWell struct {
+		time   time.Time
+		length int
+	}
+
+	var attempts []typedWell
+	for text, record := range scores.Records {
+		if !isKnownText(text) {
+			continue
+		}
+		for _, attempt := range record.Attempts {
+			if attempt.Accuracy >= comfortAccuracy && !attempt.Distractor {
+				attempts = append(attempts, typedWell{attempt.Time, len([]rune(text))})
+			}
+		}
+	}
+	if len(attempts) == 0 {
+		return 0
+	}
+
+	sort.Slice(attempts, func(i, j int) bool {
+		return attempts[i].time.After(attempts[j].time)
+	})
+	if len(attempts) > comfortAttempts {
+		attempts = attempts[:comfortAttempts]
+	}
+
+	lengths := make([]int, len(attempts))
+	for i, attempt := range attempts {
+		lengths[i] = attempt.length
+	}
+	sort.Ints(lengths)
+	return lengths[len(lengths)/2]
+}
+
+// Chooses a text in comfort mode: usually one about as long as the comfortable length,
+// now and then a longer one to stretch the player, and any text if nothing was typed well yet.
+// If no text has a length in the range aimed for, the text closest to it is chosen.
+func comfortText() textEntry {
+	texts := pool()
+	comfortable := comfortableLength()
+	if comfortable == 0 {
+		return chooseText(texts)
+	}
+
+	low, high := float64(comfortable)*(1-comfortTolerance), float64(comfortable)*(1+comfortTolerance)
+	stretching := rng.Float64() < stretchChance
+	if stretching {
+		low, high = high, float64(comfortable)*(1+stretchLimit)
+	}
+
+	var inRange []textEntry
+	for _, entry := range texts {
+		if length := float64(len([]rune(entry.text))); length >= low && length <= high {
+			inRange = append(inRange, entry)
+		}
+	}
+
+	if len(inRange) == 0 {
+		closest := texts[0]
+		distance := func(entry textEntry) float64 {
+			length := float64(len([]rune(entry.text)))
+			if length < low {
+				return low - length
+			}
+			if length > high {
+				return length - high
+			}
+			return 0
+		}
+		for _, entry := range texts {
+			if distance(entry) < distance(closest) {
+				closest = entry
+			}
+		}
+		inRange = []textEntry{closest}
+	}
+
+	if stretching {
+		fmt.Printf("A longer text to stretch yourself: you are comfortable with about %d characters\n", comfortable)
+	}
+	return chooseText(inRange)
+}
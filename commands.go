@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// A command that can be entered after a round.
+type command struct {
+	name        string
+	description string
+	// Runs the command for the text that was just typed.
+	run func(entry textEntry)
+}
+
+// The commands that can be entered after a round.
+// This also serves as the source for the help.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"review", "add the text to or remove it from your review list", toggleReview},
+		{"source", "show where the text is from", printAttribution},
+		{"help", "show the commands, game modes and current settings", func(textEntry) { printHelp() }},
+	}
+}
+
+// Adds the text to the review list or removes it from it.
+func toggleReview(entry textEntry) {
+	if scores.ToggleFavorite(entry.text) {
+		fmt.Println("Added to your review list")
+	} else {
+		fmt.Println("Removed from your review list")
+	}
+}
+
+// Prints where the text is from.
+func printAttribution(entry textEntry) {
+	if entry.source.name != "" {
+		fmt.Println("—", entry.source)
+	} else {
+		fmt.Println("It is not known where this text is from")
+	}
+}
+
+// Prints the commands, game modes and current settings.
+func printHelp() {
+	fmt.Println("\nCommands:")
+	for _, command := range commands {
+		fmt.Printf("  %-12s %s\n", command.name, command.description)
+	}
+
+	fmt.Println("\nGame modes (-mode):")
+	for _, mode := range modes {
+		fmt.Printf("  %-12s %s\n", mode.name, mode.description)
+	}
+
+	fmt.Println("\nCurrent settings:")
+	flag.VisitAll(func(f *flag.Flag) {
+		fmt.Printf("  -%s=%s\n", f.Name, f.Value)
+	})
+}
+
+// Waits for the player to continue, handling any commands entered in the meantime.
+func promptAfterRound(entry textEntry) {
+	for {
+		fmt.Println("\nPress Enter to type another text or Ctrl+C to abort")
+		if !*quiet {
+			fmt.Println(`Enter "help" to see what else you can do`)
+		}
+
+		name := strings.TrimSpace(readLineIdle(stepAway))
+		if name == "" {
+			return
+		}
+
+		found := false
+		for _, command := range commands {
+			if command.name == name {
+				command.run(entry)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			fmt.Println("Unknown command:", name)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import "strings"
+
+// The accented characters that can be composed out of a letter and a combining accent,
+// as pairs of the letter and the accented character for each accent.
+var compositions = map[rune]string{
+	'\u0300': "aà eè iì oò uù AÀ EÈ IÌ OÒ UÙ",                               // grave
+	'\u0301': "aá eé ií oó uú yý cć nń sś zź AÁ EÉ IÍ OÓ UÚ YÝ CĆ NŃ SŚ ZŹ", // acute
+	'\u0302': "aâ eê iî oô uû AÂ EÊ IÎ OÔ UÛ",                               // circumflex
+	'\u0303': "aã nñ oõ AÃ NÑ OÕ",                                           // tilde
+	'\u0308': "aä eë iï oö uü yÿ AÄ EË IÏ OÖ UÜ",                            // diaeresis
+	'\u030A': "aå uů AÅ UŮ",                                                 // ring
+	'\u0327': "cç sş CÇ SŞ",                                                 // cedilla
+	'\u030C': "cč sš zž eě rř nň CČ SŠ ZŽ EĚ RŘ NŇ",                         // caron
+}
+
+// The accents that dead keys type on their own, as sent by terminals that don't compose them with the letter typed next,
+// and the combining accents they stand for.
+var deadKeys = map[rune]rune{
+	'`': '\u0300',
+	'´': '\u0301',
+	'^': '\u0302',
+	'~': '\u0303',
+	'¨': '\u0308',
+	'°': '\u030A',
+	'¸': '\u0327',
+	'ˇ': '\u030C',
+}
+
+// Holds the letter and the combining accent each accented character of compositions is composed of.
+var decompositions = make(map[rune][2]rune)
+
+func init() {
+	for accent, pairs := range compositions {
+		for _, pair := range strings.Fields(pairs) {
+			runes := []rune(pair)
+			decompositions[runes[1]] = [2]rune{runes[0], accent}
+		}
+	}
+}
+
+// Composes the two keys into an accented character if they are a dead key's accent followed by a letter,
+// or a letter followed by a combining accent, as some terminals and input methods send them.
+func compose(first, second rune) (composed rune, ok bool) {
+	letter, accent := first, second
+	if deadAccent, isDeadKey := deadKeys[first]; isDeadKey {
+		letter, accent = second, deadAccent
+	}
+
+	for composed, parts := range decompositions {
+		if parts == [2]rune{letter, accent} {
+			return composed, true
+		}
+	}
+	return 0, false
+}
+
+// Reports whether the key may be the first half of the accented character,
+// being either the dead key of its accent or its letter, to be followed by a combining accent.
+func startsComposition(key, char rune) bool {
+	parts, ok := decompositions[char]
+	if !ok {
+		return false
+	}
+	return key == parts[0] || deadKeys[key] == parts[1]
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+)
+
+// Checks that a dead key followed by a letter and a letter followed by a combining accent both compose,
+// and that other pairs of keys don't.
+func TestCompose(t *testing.T) {
+	tests := []struct {
+		first, second rune
+		composed      rune
+		ok            bool
+	}{
+		{'´', 'e', 'é', true},
+		{'^', 'o', 'ô', true},
+		{'¨', 'U', 'Ü', true},
+		{'ˇ', 'z', 'ž', true},
+		{'`', 'a', 'à', true},
+		{'e', '\u0301', 'é', true},
+		{'n', '\u0303', 'ñ', true},
+		{'c', '\u0327', 'ç', true},
+		// Pairs that aren't compositions.
+		{'a', 'b', 0, false},
+		{'´', 'b', 0, false},
+		{'b', '\u0301', 0, false},
+		{'e', '´', 0, false},
+		{'\u0301', 'e', 0, false},
+		{'´', '´', 0, false},
+	}
+	for _, test := range tests {
+		composed, ok := compose(test.first, test.second)
+		if composed != test.composed || ok != test.ok {
+			t.Errorf("compose(%q, %q) = %q, %v, want %q, %v", test.first, test.second, composed, ok, test.composed, test.ok)
+		}
+	}
+}
+
+// Checks that the dead key of an accent and the letter under it start composing the accented character, and other keys don't.
+func TestStartsComposition(t *testing.T) {
+	tests := []struct {
+		key, char rune
+		starts    bool
+	}{
+		{'´', 'é', true},
+		{'e', 'é', true},
+		{'~', 'ñ', true},
+		{'N', 'Ñ', true},
+		{'`', 'é', false},
+		{'a', 'é', false},
+		{'n', 'Ñ', false},
+		{'e', 'e', false},
+		{'´', 'x', false},
+	}
+	for _, test := range tests {
+		if starts := startsComposition(test.key, test.char); starts != test.starts {
+			t.Errorf("startsComposition(%q, %q) = %v, want %v", test.key, test.char, starts, test.starts)
+		}
+	}
+}
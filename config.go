@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The file in the configuration directory holding the settings imported with "typer config import".
+// They are used as the defaults of the options, so the command line still overrides them.
+const configFile = "config.json"
+
+// The options that do something once rather than set up the game, which are not settings.
+var notSettings = map[string]bool{
+	"stats": true, "remaining": true, "export": true, "check": true, "clipboard": true,
+	// -ephemeral is the same as -no-save, which is exported in its place.
+	"ephemeral": true,
+}
+
+// Reports whether the option is a setting that can be exported and imported.
+func isSetting(name string) bool {
+	return !notSettings[name] && !hiddenFlags[name] && flag.Lookup(name) != nil
+}
+
+// Gets the file the settings are kept in.
+func configPath() string {
+	return filepath.Join(configDir(), configFile)
+}
+
+// Reads the settings from the JSON file, mapping the names of options to their values as given on the command line,
+// and applies them, failing on unknown options and invalid values. If it fails, none of the settings are applied.
+func applySettings(path string) (settings map[string]string, err error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(content, &settings); err != nil {
+		return nil, fmt.Errorf("%s doesn't hold settings: %v", path, err)
+	}
+
+	// The options as they were before the settings were applied, to go back to if one of them is invalid.
+	previous := make(map[string]string)
+	defer func() {
+		if err != nil {
+			for name, value := range previous {
+				flag.Set(name, value)
+			}
+		}
+	}()
+
+	for name, value := range settings {
+		if !isSetting(name) {
+			return nil, fmt.Errorf("%s is not a setting", name)
+		}
+		previous[name] = flag.Lookup(name).Value.String()
+		if err = flag.Set(name, value); err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s: %v", value, name, err)
+		}
+	}
+
+	return
+}
+
+// Applies the imported settings, if there are any.
+// If they can't be loaded, the game goes on without them, so that they can be imported again.
+func loadConfig() (err error) {
+	if _, err := os.Stat(configPath()); os.IsNotExist(err) {
+		return nil
+	}
+
+	_, err = applySettings(configPath())
+	return
+}
+
+// Reports whether the settings are being imported with "typer config import".
+func importingConfig() bool {
+	return flag.Arg(0) == "config" && flag.Arg(1) == "import"
+}
+
+// Warns that the imported settings couldn't be loaded and are left out.
+func warnConfig(err error) {
+	fmt.Println("Failed to load the settings:", err)
+	fmt.Println("They are left out until you import them again or remove", configPath())
+}
+
+// Exports or imports the settings: "config export [file]" prints the settings that differ from the defaults,
+// including the imported ones and those given on the command line, to the file or the output,
+// and "config import file" checks the settings in the file and keeps them to be used from then on.
+func runConfig(args []string) (err error) {
+	if len(args) == 2 && args[0] == "import" {
+		return importConfig(args[1])
+	}
+	if len(args) >= 1 && len(args) <= 2 && args[0] == "export" {
+		var out string
+		if len(args) == 2 {
+			out = args[1]
+		}
+		return exportConfig(out)
+	}
+	return fmt.Errorf("usage: typer [options] config export [file] or typer config import file")
+}
+
+// Writes the settings that differ from the defaults as JSON to the file, or prints them if it is empty.
+func exportConfig(out string) (err error) {
+	settings := make(map[string]string)
+	flag.VisitAll(func(option *flag.Flag) {
+		if isSetting(option.Name) && option.Value.String() != option.DefValue {
+			settings[option.Name] = option.Value.String()
+		}
+	})
+
+	// Maps are encoded with sorted keys, so the same settings always make the same file.
+	content, err := json.MarshalIndent(settings, "", "\t")
+	if err != nil {
+		return
+	}
+	content = append(content, '\n')
+
+	if out == "" {
+		fmt.Print(string(content))
+		return
+	}
+
+	if err = ioutil.WriteFile(out, content, 0644); err != nil {
+		return
+	}
+	fmt.Println("Exported", len(settings), pluralize("setting", len(settings)), "to", out)
+
+	return
+}
+
+// Checks the settings in the file and keeps them to be used from now on, replacing those imported before.
+// Invalid settings are rejected the same way as invalid options on the command line.
+func importConfig(path string) (err error) {
+	// The settings are checked on their own rather than on top of the current ones.
+	flag.VisitAll(func(option *flag.Flag) {
+		if isSetting(option.Name) {
+			flag.Set(option.Name, option.DefValue)
+		}
+	})
+
+	settings, err := applySettings(path)
+	if err != nil {
+		return
+	}
+	validateFlags()
+
+	content, err := json.MarshalIndent(settings, "", "\t")
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(configDir(), 0755); err != nil {
+		return
+	}
+	if err = ioutil.WriteFile(configPath(), append(content, '\n'), 0644); err != nil {
+		return
+	}
+
+	var names []string
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("Imported", len(settings), pluralize("setting", len(settings))+":", strings.Join(names, ", "))
+	fmt.Println("They are used from now on unless given otherwise on the command line")
+
+	return
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// The state of consistency mode.
+var consistency struct {
+	// The text being typed over and over. This is empty if a new text is to be chosen.
+	text string
+	// The results of the runs at the text so far.
+	runs []Result
+}
+
+// Records a run in consistency mode, and once -runs runs are done,
+// shows how steady the speed and accuracy were across them and moves on to the next text.
+func continueConsistency(result Result) {
+	consistency.runs = append(consistency.runs, result)
+	if len(consistency.runs) < *runs {
+		fmt.Printf("Run %d of %d done. Type it again as steadily as you can.\n", len(consistency.runs), *runs)
+		return
+	}
+
+	wpms := make([]float64, len(consistency.runs))
+	accuracies := make([]float64, len(consistency.runs))
+	for i, run := range consistency.runs {
+		wpms[i] = run.wpm
+		accuracies[i] = run.accuracy
+	}
+
+	wpmMean, wpmDeviation := meanAndDeviation(wpms)
+	accuracyMean, accuracyDeviation := meanAndDeviation(accuracies)
+	fmt.Printf("Across %d runs: %.1f ± %.1f WPM, %.1f ± %.1f%% accuracy\n",
+		len(consistency.runs), wpmMean, wpmDeviation, accuracyMean, accuracyDeviation)
+
+	consistency.text = ""
+	consistency.runs = nil
+}
+
+// Calculates the mean and the standard deviation of the values.
+func meanAndDeviation(values []float64) (mean, deviation float64) {
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+
+	for _, value := range values {
+		deviation += (value - mean) * (value - mean)
+	}
+	deviation = math.Sqrt(deviation / float64(len(values)))
+	return
+}
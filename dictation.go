@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// The text-to-speech programs tried in order to speak the words in dictation mode.
+// Each is run with the words to speak as its only argument.
+var speakers = []string{"espeak-ng", "espeak", "spd-say", "say"}
+
+// The state of dictation mode.
+var dictation struct {
+	// The text-to-speech program the words are spoken with. This is empty if the words are only shown.
+	speaker string
+	// How many words of the text being typed were dictated so far.
+	dictated int
+}
+
+// Looks for a text-to-speech program to dictate with unless -no-speech is given.
+func setUpDictation() {
+	if !*noSpeech {
+		for _, speaker := range speakers {
+			if _, err := exec.LookPath(speaker); err == nil {
+				dictation.speaker = speaker
+				break
+			}
+		}
+	}
+
+	if dictation.speaker == "" {
+		fmt.Println("The words will be shown as they are dictated at", *dictationWPM, "WPM")
+	} else {
+		fmt.Println("The words will be spoken with", dictation.speaker, "and shown as they are dictated at", *dictationWPM, "WPM")
+	}
+}
+
+// The shortest time between two words being dictated. Anything shorter could round down to no time at all.
+const minWordInterval = time.Millisecond
+
+// Gets the time between two words being dictated.
+func wordInterval() time.Duration {
+	return time.Minute / time.Duration(*dictationWPM)
+}
+
+// Dictates the words of the text that are due after the given time from the start of the round and weren't dictated yet.
+// The first word is dictated right away. Returns whether any word was.
+func dictate(text string, elapsed time.Duration) bool {
+	words := strings.Split(text, " ")
+	due := 1 + int(elapsed/wordInterval())
+	if due > len(words) {
+		due = len(words)
+	}
+	if due <= dictation.dictated {
+		return false
+	}
+
+	speak(strings.Join(words[dictation.dictated:due], " "))
+	dictation.dictated = due
+	return true
+}
+
+// Speaks the words in the background if there is a text-to-speech program.
+// Failing to do so is not worth interrupting the round for, as the words are shown anyway.
+func speak(words string) {
+	if dictation.speaker == "" {
+		return
+	}
+
+	cmd := exec.Command(dictation.speaker, words)
+	if cmd.Start() == nil {
+		go cmd.Wait()
+	}
+}
+
+// Measures how far behind the dictation the player was on average,
+// from when each word was dictated to when the word in its place in the input was typed to its end.
+func dictationLag(input []rune, typedAt []time.Duration) time.Duration {
+	var total time.Duration
+	words := 0
+	for i, r := range input {
+		if r == ' ' || (i+1 < len(input) && input[i+1] != ' ') {
+			continue
+		}
+
+		lag := typedAt[i] - time.Duration(words)*wordInterval()
+		if lag > 0 {
+			total += lag
+		}
+		words++
+	}
+
+	if words == 0 {
+		return 0
+	}
+	return total / time.Duration(words)
+}
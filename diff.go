@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The kind of an edit operation turning the input into the text.
+type editKind int
+
+const (
+	editMatch        editKind = iota
+	editSubstitution          // a character was typed wrong
+	editInsertion             // an extra character was typed
+	editDeletion              // a character of the text was left out
+	// Two neighboring characters were typed the wrong way around, with -distance damerau.
+	// A transposition is made of two operations, one for each character, but counts as one mistake.
+	editTransposition
+)
+
+// An edit operation at a position of the alignment of the input and the text.
+type editOp struct {
+	kind editKind
+	// The typed character. This is 0 for deletions.
+	typed rune
+	// The character of the text. This is 0 for insertions.
+	want rune
+}
+
+// Aligns the input with the text and gets the edit operations turning the input into the text.
+// The number of operations that are not matches equals the distance measured with the -distance algorithm,
+// counting the two operations of a transposition as one.
+func editOps(input, text string) []editOp {
+	a, b := []rune(input), []rune(text)
+	transposes := *distanceMetric == "damerau"
+	// Reports whether the characters before a[i] and b[j] are the same two characters the wrong way around.
+	swapped := func(i, j int) bool {
+		return transposes && i > 1 && j > 1 && a[i-1] != b[j-1] && a[i-1] == b[j-2] && a[i-2] == b[j-1]
+	}
+
+	// dist[i][j] is the distance between a[:i] and b[:j].
+	dist := make([][]int, len(a)+1)
+	for i := range dist {
+		dist[i] = make([]int, len(b)+1)
+		dist[i][0] = i
+	}
+	for j := range dist[0] {
+		dist[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			dist[i][j] = minInt(dist[i-1][j-1]+cost, minInt(dist[i-1][j]+1, dist[i][j-1]+1))
+			if swapped(i, j) {
+				dist[i][j] = minInt(dist[i][j], dist[i-2][j-2]+1)
+			}
+		}
+	}
+
+	// Walk back from the end to recover the operations.
+	var ops []editOp
+	i, j := len(a), len(b)
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && a[i-1] == b[j-1] && dist[i][j] == dist[i-1][j-1]:
+			ops = append(ops, editOp{editMatch, a[i-1], b[j-1]})
+			i, j = i-1, j-1
+		case swapped(i, j) && dist[i][j] == dist[i-2][j-2]+1:
+			ops = append(ops, editOp{editTransposition, a[i-1], b[j-1]}, editOp{editTransposition, a[i-2], b[j-2]})
+			i, j = i-2, j-2
+		case i > 0 && j > 0 && dist[i][j] == dist[i-1][j-1]+1:
+			ops = append(ops, editOp{editSubstitution, a[i-1], b[j-1]})
+			i, j = i-1, j-1
+		case i > 0 && dist[i][j] == dist[i-1][j]+1:
+			ops = append(ops, editOp{editInsertion, a[i-1], 0})
+			i--
+		default:
+			ops = append(ops, editOp{editDeletion, 0, b[j-1]})
+			j--
+		}
+	}
+
+	// Reverse the operations so that they are in order.
+	for l, r := 0, len(ops)-1; l < r; l, r = l+1, r-1 {
+		ops[l], ops[r] = ops[r], ops[l]
+	}
+
+	return ops
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Gets the characters of the text that were typed wrong or left out.
+func missedChars(ops []editOp) []rune {
+	var missed []rune
+	for _, op := range ops {
+		if op.kind == editSubstitution || op.kind == editDeletion || op.kind == editTransposition {
+			missed = append(missed, op.want)
+		}
+	}
+	return missed
+}
+
+// Counts the edits of each kind, which add up to the distance.
+func countEdits(ops []editOp) (substitutions, insertions, deletions, transpositions int) {
+	for _, op := range ops {
+		switch op.kind {
+		case editSubstitution:
+			substitutions++
+		case editInsertion:
+			insertions++
+		case editDeletion:
+			deletions++
+		case editTransposition:
+			transpositions++
+		}
+	}
+	// Both characters of a transposition have an operation.
+	transpositions /= 2
+	return
+}
+
+// The character shown in place of a character that is not there in the aligned diff.
+const gapChar = '_'
+
+// Gets a visible stand-in for line breaks and tabs so that they don't break the alignment.
+func visibleRune(r rune) rune {
+	switch r {
+	case '\n':
+		return '↵'
+	case '\t':
+		return '→'
+	default:
+		return r
+	}
+}
+
+// Prints the input aligned with the text, marking each mistake,
+// followed by how many mistakes of each kind were made.
+func printDiff(ops []editOp) {
+	var typed, want, marks strings.Builder
+	for _, op := range ops {
+		typedChar, wantChar, mark := op.typed, op.want, ' '
+		switch op.kind {
+		case editSubstitution:
+			mark = '^'
+		case editInsertion:
+			wantChar, mark = gapChar, '+'
+		case editDeletion:
+			typedChar, mark = gapChar, '-'
+		case editTransposition:
+			mark = '~'
+		}
+		if op.kind == editMatch {
+			typed.WriteString(paint(roleCorrect, string(visibleRune(typedChar))))
+		} else {
+			typed.WriteString(paint(roleIncorrect, string(visibleRune(typedChar))))
+		}
+		want.WriteRune(visibleRune(wantChar))
+		marks.WriteRune(mark)
+	}
+
+	fmt.Println("Typed:", typed.String())
+	fmt.Println("Text: ", want.String())
+	fmt.Println("      ", strings.TrimRight(marks.String(), " "))
+	substitutions, insertions, deletions, transpositions := countEdits(ops)
+	if *distanceMetric == "damerau" {
+		fmt.Println("(^ wrong, + extra, - missing, ~ swapped)")
+		fmt.Printf("%d %s, %d %s, %d %s, %d %s\n",
+			substitutions, pluralize("wrong character", substitutions),
+			insertions, pluralize("extra character", insertions),
+			deletions, pluralize("missing character", deletions),
+			transpositions, pluralize("swapped pair", transpositions))
+		return
+	}
+
+	fmt.Println("(^ wrong, + extra, - missing)")
+	fmt.Printf("%d %s, %d %s, %d %s\n",
+		substitutions, pluralize("wrong character", substitutions),
+		insertions, pluralize("extra character", insertions),
+		deletions, pluralize("missing character", deletions))
+}
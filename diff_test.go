@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+// Checks the breakdown of the mistakes into wrong, extra, missing and swapped characters,
+// and that it adds up to the distance measured with the same algorithm.
+func TestCountEdits(t *testing.T) {
+	tests := []struct {
+		algorithm                                            string
+		input, text                                          string
+		substitutions, insertions, deletions, transpositions int
+	}{
+		{"levenshtein", "the", "the", 0, 0, 0, 0},
+		{"levenshtein", "teh", "the", 2, 0, 0, 0},
+		{"damerau", "teh", "the", 0, 0, 0, 1},
+		{"levenshtein", "helo wrold", "hello world", 2, 0, 1, 0},
+		{"damerau", "helo wrold", "hello world", 0, 0, 1, 1},
+		{"damerau", "hxello", "hello", 0, 1, 0, 0},
+		{"damerau", "", "abc", 0, 0, 3, 0},
+		{"damerau", "abc", "", 0, 3, 0, 0},
+		{"damerau", "ab", "ba", 0, 0, 0, 1},
+		{"damerau", "abcd", "badc", 0, 0, 0, 2},
+		{"damerau", "日本", "本日", 0, 0, 0, 1},
+	}
+
+	defer func(metricWas string) { *distanceMetric = metricWas }(*distanceMetric)
+	for _, test := range tests {
+		*distanceMetric = test.algorithm
+		substitutions, insertions, deletions, transpositions := countEdits(editOps(test.input, test.text))
+		if substitutions != test.substitutions || insertions != test.insertions || deletions != test.deletions || transpositions != test.transpositions {
+			t.Errorf("%s edits turning %q into %q: %d wrong, %d extra, %d missing, %d swapped; want %d, %d, %d, %d",
+				test.algorithm, test.input, test.text, substitutions, insertions, deletions, transpositions,
+				test.substitutions, test.insertions, test.deletions, test.transpositions)
+		}
+		if sum, distance := substitutions+insertions+deletions+transpositions, computeDistance(test.input, test.text); sum != distance {
+			t.Errorf("%s edits turning %q into %q add up to %d, but the distance is %d", test.algorithm, test.input, test.text, sum, distance)
+		}
+	}
+}
+
+// Checks that the operations cover every character of the input and the text, in order.
+func TestEditOpsCoverBoth(t *testing.T) {
+	defer func(metricWas string) { *distanceMetric = metricWas }(*distanceMetric)
+	for _, algorithm := range []string{"levenshtein", "damerau"} {
+		*distanceMetric = algorithm
+		for _, pair := range [][2]string{{"helo wrold", "hello world"}, {"abcd", "badc"}, {"", "abc"}, {"日本語", "本日"}} {
+			var typed, wanted []rune
+			for _, op := range editOps(pair[0], pair[1]) {
+				if op.kind != editDeletion {
+					typed = append(typed, op.typed)
+				}
+				if op.kind != editInsertion {
+					wanted = append(wanted, op.want)
+				}
+			}
+			if string(typed) != pair[0] || string(wanted) != pair[1] {
+				t.Errorf("%s operations turning %q into %q cover %q and %q", algorithm, pair[0], pair[1], string(typed), string(wanted))
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/agnivade/levenshtein"
+)
+
+// The algorithm the distance is measured with unless -distance chooses another, which all distances were measured with before there was a choice.
+const defaultDistance = "levenshtein"
+
+// The algorithms the distance between the input and the text can be measured with, chosen with -distance.
+var distanceAlgorithms = []struct {
+	name        string
+	description string
+	compute     func(a, b string) int
+}{
+	{defaultDistance, "counting each wrong, extra and missing character", levenshtein.ComputeDistance},
+	{"damerau", "like levenshtein, but counting two neighboring characters typed the wrong way around as one mistake", damerauDistance},
+}
+
+// Describes the distance algorithms for the usage message.
+func describeDistanceAlgorithms() string {
+	var descriptions []string
+	for _, algorithm := range distanceAlgorithms {
+		descriptions = append(descriptions, algorithm.name+" "+algorithm.description)
+	}
+	return "how to count the characters the input is off by: " + strings.Join(descriptions, ", or ") + " (highscores are kept for each separately)"
+}
+
+// Gets the function computing the distance with the algorithm, which is nil if there is no such algorithm.
+func distanceFunc(name string) func(a, b string) int {
+	for _, algorithm := range distanceAlgorithms {
+		if algorithm.name == name {
+			return algorithm.compute
+		}
+	}
+	return nil
+}
+
+// Computes the distance between the strings with the -distance algorithm.
+func computeDistance(a, b string) int {
+	return distanceFunc(*distanceMetric)(a, b)
+}
+
+// Computes the Damerau-Levenshtein distance between the strings in its restricted form, also known as the optimal string alignment distance:
+// the fewest characters that have to be substituted, inserted, deleted or swapped with their neighbor to turn one string into the other,
+// with no character being edited more than once. For example, "teh" is 1 off "the" rather than 2.
+func damerauDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+
+	// The distances between the prefixes of s and t, one row for each prefix of s, of which only the last three are needed.
+	previous2 := make([]int, len(t)+1)
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			distance := previous[j-1] + cost // substitution
+			if previous[j]+1 < distance {
+				distance = previous[j] + 1 // deletion
+			}
+			if current[j-1]+1 < distance {
+				distance = current[j-1] + 1 // insertion
+			}
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] && previous2[j-2]+1 < distance {
+				distance = previous2[j-2] + 1 // transposition
+			}
+			current[j] = distance
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+
+	return previous[len(t)]
+}
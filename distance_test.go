@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+// Checks that two neighboring characters typed the wrong way around are one mistake under Damerau,
+// counted in characters rather than bytes.
+func TestDamerauDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"teh", "the", 1},
+		{"the", "the", 0},
+		{"", "the", 3},
+		{"the", "", 3},
+		{"ab", "ba", 1},
+		{"abcd", "badc", 2},
+		{"ca", "abc", 3},
+		{"helo wrold", "hello world", 2},
+		{"日本", "本日", 1},
+		{"café", "cafe", 1},
+	}
+	for _, test := range tests {
+		if distance := damerauDistance(test.a, test.b); distance != test.distance {
+			t.Errorf("damerauDistance(%q, %q) = %d, want %d", test.a, test.b, distance, test.distance)
+		}
+	}
+}
+
+// Checks that each algorithm of -distance measures by its own rules and that unknown ones aren't found.
+func TestDistanceFunc(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		a, b      string
+		distance  int
+	}{
+		{"levenshtein", "teh", "the", 2},
+		{"damerau", "teh", "the", 1},
+		{"levenshtein", "ab", "ba", 2},
+		{"damerau", "ab", "ba", 1},
+		{"levenshtein", "kitten", "sitting", 3},
+		{"damerau", "kitten", "sitting", 3},
+	}
+	for _, test := range tests {
+		measure := distanceFunc(test.algorithm)
+		if measure == nil {
+			t.Errorf("distanceFunc(%q) = nil", test.algorithm)
+			continue
+		}
+		if distance := measure(test.a, test.b); distance != test.distance {
+			t.Errorf("%s distance between %q and %q = %d, want %d", test.algorithm, test.a, test.b, distance, test.distance)
+		}
+	}
+
+	if distanceFunc("hamming") != nil {
+		t.Error(`distanceFunc("hamming") isn't nil`)
+	}
+}
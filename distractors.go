@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// The state of the distractor served in place of a text with -distractors.
+var distraction struct {
+	// The text the distractor was made from. This is empty if the text being typed is not a distractor.
+	original string
+	// The words that were swapped.
+	swapped [2]string
+}
+
+// Chooses whether texts are served as distractors and how they are changed.
+// It is seeded with -distractor-seed if given, so that the same distractors are served every time.
+var distractorRng *rand.Rand
+
+// Makes the distractors deterministic if -distractor-seed is given.
+func setUpDistractors() {
+	seed := *distractorSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	distractorRng = rand.New(rand.NewSource(seed))
+}
+
+// Serves the text as a distractor with the chance given with -distractors, swapping two neighboring words that differ,
+// so that it takes reading the text rather than typing it from memory to get it right.
+// A text that has no such words is served as it is.
+func distract(text string) string {
+	distraction.original = ""
+	if *distractors <= 0 || distractorRng.Float64() >= *distractors {
+		return text
+	}
+
+	words := strings.Split(text, " ")
+	var candidates []int
+	for i := 0; i+1 < len(words); i++ {
+		if words[i] != words[i+1] {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return text
+	}
+
+	i := candidates[distractorRng.Intn(len(candidates))]
+	distraction.original = text
+	distraction.swapped = [2]string{words[i], words[i+1]}
+	words[i], words[i+1] = words[i+1], words[i]
+	return strings.Join(words, " ")
+}
+
+// Adds the attempt at a distractor to the record of the text it was made from, marked as such.
+func recordDistractor(original string, result Result) {
+	if _, exists := scores.Records[original]; !exists {
+		scores.Records[original] = &Record{}
+	}
+
+	attempt := newAttempt(result, now())
+	attempt.Distractor = true
+	scores.addAttempt(original, attempt)
+}
+
+// Tells the player that the text they typed was a distractor and whether they typed what was shown or fell for it,
+// typing the text as they knew it instead.
+func revealDistraction(shown string, result Result) {
+	fmt.Printf("That was a distractor: %q and %q were swapped\n", distraction.swapped[0], distraction.swapped[1])
+
+	if result.input != shown && computeDistance(result.input, distraction.original) < result.distance {
+		fmt.Println("You typed the text as you knew it rather than as it was shown. Read carefully!")
+	} else if result.distance == 0 {
+		fmt.Println("Well read!")
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// How many texts the exported report lists.
+const exportedTexts = 10
+
+// How many of the most recent sessions the exported report lists.
+const exportedSessions = 10
+
+// How many characters of a text are shown in a table before it is cut off.
+const exportedTextLength = 40
+
+// Prints a report of the stats in Markdown: the overall stats, the texts with the highest highscores and the most recent sessions.
+// The report only depends on the saved scores, so exporting the same scores always gives the same report.
+func exportMarkdown() {
+	var texts []string
+	attempts := 0
+	var totalWPM, totalAccuracy, bestWPM float64
+	for text, record := range scores.Records {
+		texts = append(texts, text)
+		for _, attempt := range record.Attempts {
+			attempts++
+			totalWPM += attempt.WPM
+			totalAccuracy += attempt.Accuracy
+			if attempt.WPM > bestWPM {
+				bestWPM = attempt.WPM
+			}
+		}
+	}
+
+	fmt.Println("# typer stats")
+	fmt.Println()
+	fmt.Println("## Overall")
+	fmt.Println()
+	fmt.Println("| Texts | Attempts | Mean WPM | Mean accuracy | Best WPM |")
+	fmt.Println("|------:|---------:|---------:|--------------:|---------:|")
+	if attempts > 0 {
+		fmt.Printf("| %d | %d | %.1f | %.1f%% | %.1f |\n",
+			len(texts), attempts, totalWPM/float64(attempts), totalAccuracy/float64(attempts), bestWPM)
+	} else {
+		fmt.Printf("| %d | 0 | - | - | - |\n", len(texts))
+	}
+
+	sort.Slice(texts, func(i, j int) bool {
+		a, b := scores.Records[texts[i]], scores.Records[texts[j]]
+		if a.highscore(*distanceMetric) != b.highscore(*distanceMetric) {
+			return a.highscore(*distanceMetric) > b.highscore(*distanceMetric)
+		}
+		return texts[i] < texts[j]
+	})
+	if len(texts) > exportedTexts {
+		texts = texts[:exportedTexts]
+	}
+
+	if len(texts) > 0 {
+		fmt.Println()
+		fmt.Println("## Top texts")
+		fmt.Println()
+		fmt.Println("| Text | Highscore | Attempts | Difficulty |")
+		fmt.Println("|------|----------:|---------:|------------|")
+		for _, text := range texts {
+			record := scores.Records[text]
+			difficulty := record.Difficulty
+			if difficulty == "" {
+				difficulty = "unrated"
+			}
+			fmt.Printf("| %s | %d | %d | %s |\n", markdownCell(text), record.highscore(*distanceMetric), len(record.Attempts), difficulty)
+		}
+	}
+
+	sessions := scores.Sessions
+	if len(sessions) > exportedSessions {
+		sessions = sessions[len(sessions)-exportedSessions:]
+	}
+
+	if len(sessions) > 0 {
+		fmt.Println()
+		fmt.Println("## Recent sessions")
+		fmt.Println()
+		fmt.Println("| Date | Rounds | WPM | Accuracy |")
+		fmt.Println("|------|-------:|----:|---------:|")
+		for _, session := range sessions {
+			fmt.Printf("| %s | %d | %.1f | %.1f%% |\n",
+				session.Time.Format("2006-01-02 15:04"), session.Rounds, session.WPM, session.Accuracy)
+		}
+	}
+}
+
+// Gets the text as it can be put into a Markdown table cell: on one line, with pipes escaped and cut off if too long.
+func markdownCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > exportedTextLength {
+		text = string(runes[:exportedTextLength-1]) + "…"
+	}
+	return strings.ReplaceAll(text, "|", "\\|")
+}
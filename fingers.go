@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// The fingers that press the keys, from the left pinky to the right pinky, and the thumbs pressing Space.
+var fingers = []string{"left pinky", "left ring", "left middle", "left index", "right index", "right middle", "right ring", "right pinky", "thumbs"}
+
+// The index of the thumbs in fingers.
+const thumbs = 8
+
+// Which finger presses the keys of each column of a row in touch typing, as indices into fingers.
+// The index fingers each cover two columns and the right pinky covers everything right of the ring finger.
+var columnFingers = []int{0, 1, 2, 3, 3, 4, 4, 5, 6, 7, 7, 7, 7}
+
+// The keyboard layouts that can be given with -layout: the characters of each row of keys from the number row down,
+// starting with the key pressed by the left pinky, once as typed and once as typed with Shift.
+var layouts = []struct {
+	name    string
+	rows    []string
+	shifted []string
+}{
+	{"qwerty",
+		[]string{"1234567890-=", `qwertyuiop[]\`, "asdfghjkl;'", "zxcvbnm,./"},
+		[]string{"!@#$%^&*()_+", "QWERTYUIOP{}|", `ASDFGHJKL:"`, "ZXCVBNM<>?"}},
+	{"dvorak",
+		[]string{"1234567890[]", `',.pyfgcrl/=\`, "aoeuidhtns-", ";qjkxbmwvz"},
+		[]string{"!@#$%^&*(){}", `"<>PYFGCRL?+|`, "AOEUIDHTNS_", ":QJKXBMWVZ"}},
+	{"colemak",
+		[]string{"1234567890-=", `qwfpgjluy;[]\`, "arstdhneio'", "zxcvbkm,./"},
+		[]string{"!@#$%^&*()_+", "QWFPGJLUY:{}|", `ARSTDHNEIO"`, "ZXCVBKM<>?"}},
+}
+
+// Describes the layouts for the usage message.
+func describeLayouts() string {
+	var names []string
+	for _, layout := range layouts {
+		names = append(names, layout.name)
+	}
+	return "the keyboard layout you type on, to show how the keys of a session were spread across your fingers and to draw in guided mode: " + strings.Join(names, ", ")
+}
+
+// Gets the finger pressing the key of each character on the layout, which is nil if there is no such layout.
+func fingerMap(name string) map[rune]int {
+	for _, layout := range layouts {
+		if layout.name != name {
+			continue
+		}
+
+		fingerOf := map[rune]int{' ': thumbs, '\t': 0, '`': 0, '~': 0, '\n': 7}
+		for _, rows := range [][]string{layout.rows, layout.shifted} {
+			for _, row := range rows {
+				for column, r := range []rune(row) {
+					fingerOf[r] = columnFingers[column]
+				}
+			}
+		}
+		return fingerOf
+	}
+	return nil
+}
+
+// The layout the stats show the workload of the fingers on unless -layout is given.
+const defaultLayout = "qwerty"
+
+// Records how often each character was typed.
+func (scores Scores) RecordKeys(input string) {
+	for _, r := range input {
+		scores.Keys[string(r)]++
+	}
+}
+
+// A finger is overused if it presses this many times as many keys as the fingers do on average, the thumbs left out.
+const overuseFactor = 1.5
+
+// Prints how the keys typed, given by how often each character was typed, were spread across the fingers on the layout,
+// pointing out the fingers that were overused. Keys that aren't a single character, such as from an edited scores file, are left out.
+func printFingerWorkload(keys map[string]int, layout string) {
+	fingerOf := fingerMap(layout)
+
+	workload := make([]int, len(fingers))
+	total := 0
+	for char, times := range keys {
+		if utf8.RuneCountInString(char) != 1 {
+			continue
+		}
+		if finger, ok := fingerOf[[]rune(char)[0]]; ok {
+			workload[finger] += times
+			total += times
+		}
+	}
+	if total == 0 {
+		return
+	}
+
+	// The thumbs only press Space, so they are left out of the average.
+	average := float64(total-workload[thumbs]) / float64(len(fingers)-1)
+
+	fmt.Println("Finger workload on", layout+":")
+	for finger, name := range fingers {
+		share := float64(workload[finger]) / float64(total) * 100
+		if finger != thumbs && float64(workload[finger]) > average*overuseFactor {
+			fmt.Printf("  %-13s %5.1f%% (overused)\n", name, share)
+		} else {
+			fmt.Printf("  %-13s %5.1f%%\n", name, share)
+		}
+	}
+}
+
+// Counts how often each character was typed in the rounds of the session that count toward the stats.
+func sessionKeys() map[string]int {
+	keys := make(map[string]int)
+	for _, result := range session.results {
+		for _, r := range result.input {
+			keys[string(r)]++
+		}
+	}
+	return keys
+}
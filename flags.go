@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Command-line options.
+var (
+	rawMode         = flag.Bool("raw", false, "read input key by key instead of line by line (requires a terminal)")
+	mode            = flag.String("mode", "normal", describeModes())
+	pretty          = flag.Bool("pretty", false, "save the scores as indented JSON")
+	noSave          = flag.Bool("no-save", false, "don't load or save any scores, keeping them in memory for this session only")
+	gzipped         = flag.Bool("gzip", false, "save the scores gzip-compressed")
+	leaderboard     = flag.String("leaderboard", "", "a leaderboard file shared with other players to add your results to")
+	name            = flag.String("name", "", "your name on the leaderboard (defaults to your user name)")
+	maxAttempts     = flag.Int("max-attempts", 0, "in drill mode, move on after this many failed attempts at a text (0 means unlimited)")
+	printableOnly   = flag.Bool("printable-only", false, "count only printable characters, excluding leading and trailing whitespace, for the speed and accuracy")
+	fromClipboard   = flag.Bool("clipboard", false, "type the text in the clipboard instead, one text per line")
+	verbose         = flag.Bool("verbose", false, "show exactly which characters were wrong, extra or missing after a round")
+	smoothCountdown = flag.Bool("smooth-countdown", false, "animate the countdown on a single line (only if the output is a terminal)")
+	journal         = flag.String("log", "", "a file to append a line about each round to")
+	distanceMetric  = flag.String("distance", defaultDistance, describeDistanceAlgorithms())
+	scoring         = flag.String("scoring", "fixed", "how rounds are scored: fixed, clock for points that decay the longer you take, or length for points per correct character so that longer texts are worth more")
+	clockDecay      = flag.Float64("clock-decay", 50, "with -scoring clock, the points lost per second")
+	clockFloor      = flag.Int("clock-floor", 200, "with -scoring clock, the least points a round can be worth before mistakes are subtracted")
+	order           = flag.String("order", "random", "the order the texts are served in: random, sequential for the order they are given in, or shuffle for an order shuffled once at the start")
+	balanced        = flag.Bool("balanced", false, "choose the texts served the least this session more often so that none are left out for long")
+	noRepeatWindow  = flag.Int("no-repeat-window", 1, "don't serve any of this many texts served last again (as many as there are texts to choose from allow)")
+	sprintStartWPM  = flag.Float64("sprint-wpm", 30, "in sprint mode, the speed the first text has to be typed at")
+	sprintStep      = flag.Float64("sprint-step", 5, "in sprint mode, how much faster each text has to be typed than the one before it, in WPM")
+	runs            = flag.Int("runs", 5, "in consistency mode, how many times each text is typed")
+	allowRepeat     = flag.Bool("allow-repeat", false, "choose texts uniformly at random, which may serve the same text twice in a row")
+	showStats       = flag.Bool("stats", false, "print the records of all texts typed so far and exit")
+	export          = flag.String("export", "", "print a report of your stats in this format and exit: md for Markdown")
+	showRemaining   = flag.Bool("remaining", false, "list the texts you have yet to type without any mistakes and exit")
+	showDifficulty  = flag.Bool("show-difficulty", false, "show how hard a text is for you before typing it")
+	stopOnError     = flag.Bool("stop-on-error", false, "don't accept a wrong key until the right one is pressed, counting each wrong key as a mistake (raw mode)")
+	count           = flag.Int("count", 0, "end the session after this many rounds (0 means no limit)")
+	distractors     = flag.Float64("distractors", 0, "in normal mode, the chance of a text being served with two of its words swapped, to train reading it rather than typing it from memory, from 0 to 1")
+	distractorSeed  = flag.Int64("distractor-seed", 0, "the seed for choosing the distractors, so that the same ones are served every time (0 means a random seed)")
+	untilScore      = flag.Int("until-score", 0, "end the session once the rounds that count toward your stats add up to this score (0 means no limit)")
+	verifiedStreak  = flag.Int("verified-highscore", 0, "only keep a score as a highscore if it was made during a streak of at least this many rounds in a row without any mistakes, counting the round itself (0 means any score)")
+	milestoneStep   = flag.Int("milestone-step", 10, "celebrate the first round typed at each multiple of this many WPM with at least 95% accuracy (0 means none)")
+	milestoneList   = flag.String("milestones", "", "more speeds in WPM to celebrate the first round typed at, separated by commas, such as 75,120")
+	minAccuracy     = flag.Float64("min-accuracy", 0, "only count rounds with at least this accuracy in percent toward your stats and highscores")
+	paceWPM         = flag.Int("pace-wpm", 0, "show a marker moving across the text at this many WPM to keep up with (raw mode)")
+	dictationWPM    = flag.Int("dictation-wpm", 30, "in dictation mode, how many words a minute are dictated")
+	noSpeech        = flag.Bool("no-speech", false, "in dictation mode, only show the words as they are dictated instead of also speaking them")
+	liveWPM         = flag.Bool("live-wpm", false, "show your speed so far below the text while typing (raw mode)")
+	maxHistory      = flag.Int("max-history", 0, "keep only this many of the most recent attempts at each text when saving (0 means no limit); highscores, fastest times and clean records are kept")
+	maxTexts        = flag.Int("max-texts", 0, "keep the records of only this many of the texts played most recently when saving (0 means no limit)")
+	profile         = flag.String("profile", "", "the profile to keep separate scores for (letters, digits, - and _ only)")
+	headless        = flag.Bool("headless", false, "play with another program instead of a player, reading commands from the input and writing JSON responses, one per line (see the README)")
+	saveSessionTo   = flag.String("save-session", "", "a file to save the session to after every round, to resume it with -load-session even after quitting")
+	loadSessionFrom = flag.String("load-session", "", "a file a session was saved to with -save-session, to resume it where it was left off")
+	players         = flag.String("players", "", "the names of players to take turns typing the same text each round, separated by commas, each keeping their scores in the profile of their name")
+	charCount       = flag.Int("chars", 30, "in numbers, symbols and ngrams mode, how many characters to type each round")
+	ngramSet        = flag.String("ngrams", "", "in ngrams mode, the letter sequences to drill, separated by commas, instead of the most common ones of English")
+	ngramLength     = flag.Int("ngram-length", 0, "in ngrams mode, only drill the common letter sequences of this length, from 2 to 4 (0 means all)")
+	textsFile       = flag.String("texts", "", "a file to take the texts to be typed from, one per line (in code mode, snippets separated by blank lines); a .json file holds a list of texts with where they are from and their tags as in [{\"text\": \"A text.\", \"source\": \"Author\", \"url\": \"https://example.com\", \"tags\": [\"quotes\"]}]")
+	textsURL        = flag.String("url", "", "an HTTP(S) URL to fetch prose from, split into sentences to be typed; what was fetched is kept to use offline")
+	sourcesSpec     = flag.String("sources", "", describeSources())
+	sentences       = flag.Bool("sentences", false, "with -texts, read the file as prose and split it into sentences")
+	tag             = flag.String("tag", "", "only type texts with this tag, as given to them in a -texts file in the JSON format")
+	minLength       = flag.Int("min-length", 0, "only type texts with at least this many characters")
+	maxLength       = flag.Int("max-length", 0, "only type texts with at most this many characters (0 means no limit)")
+	breakReminder   = flag.Duration("break-reminder", 0, "suggest taking a break after typing for this long since the last one, such as 20m (0 means never)")
+	breakRounds     = flag.Int("break-rounds", 0, "suggest taking a break after this many rounds since the last one (0 means never)")
+	breakWait       = flag.Bool("break-wait", false, "with -break-reminder or -break-rounds, wait until you are back from the break before going on")
+	idleAfter       = flag.Duration("idle", 0, "after waiting this long for you between rounds, save the scores and show how the session went (0 means never)")
+	idleAction      = flag.String("idle-action", "save", "what to do after -idle: save to save and wait on, or exit to end the session")
+	check           = flag.Bool("check", false, "check the texts, reporting how many are usable and how many were left out, and exit")
+	// Changing these makes new scores incomparable to ones saved with other values.
+	baseScore       = flag.Int("base-score", 1000, "the points a perfectly typed round is worth")
+	penalty         = flag.Int("penalty", 100, "the points each character off costs")
+	metronome       = flag.Int("metronome", 0, "ring the terminal bell this many times a minute to type to, scoring how well your keys kept the beat (raw mode)")
+	noBell          = flag.Bool("no-bell", false, "never ring the terminal bell")
+	showKPS         = flag.Bool("kps", false, "show how many keys you pressed per second, including corrections, after a round (raw mode)")
+	highlightNext   = flag.Bool("highlight-next", false, "highlight the next character to type (raw mode)")
+	showProgress    = flag.Bool("progress", false, "show how much of the text you typed correctly so far below it while typing (raw mode)")
+	fairStart       = flag.Bool("fair-start", false, "start the clock only once the first character of the text is typed right rather than when the text is shown (raw mode)")
+	filterChatter   = flag.Bool("filter-chatter", false, "ignore a key pressed again faster than -chatter-threshold, as happens when a key sticks or chatters (raw mode)")
+	chatterGap      = flag.Duration("chatter-threshold", 35*time.Millisecond, "with -filter-chatter, the least time between two presses of the same key for both to count")
+	exactWhitespace = flag.Bool("exact-whitespace", false, "count leading and trailing whitespace you type as mistakes too instead of ignoring it")
+	hyperlinks      = flag.Bool("hyperlinks", false, "make links clickable in terminals that support it")
+	noColor         = flag.Bool("no-color", false, "don't use colors")
+	theme           = flag.String("theme", "default", describeThemes())
+	keyboardLayout  = flag.String("layout", "", describeLayouts())
+	submit          = flag.String("submit", "enter", "how to finish typing a text: enter for Enter, which types a line break while a text spanning several lines isn't done, ctrl-d for Ctrl+D (raw mode), or double-enter for pressing Enter twice")
+	tabWidth        = flag.Int("tab-width", 0, "make Tab type this many spaces instead of a tab in raw mode, to match texts indented with spaces")
+	review          = flag.Bool("review", false, "at the end of the session, review the rounds with the most mistakes, which are kept until then")
+	noEcho          = flag.Bool("no-echo", false, "hide what you type until the end of the round, to type texts from memory or for privacy (raw mode)")
+	showTarget      = flag.Bool("show-target", false, "after a round with mistakes, show the text and what you typed one above the other")
+	quiet           = flag.Bool("quiet", false, "leave out hints and optional details such as those of -show-target")
+	simulateWPM     = flag.Float64("simulate", 0, "play against a simulated player typing at this many WPM instead of reading the input, saving nothing (for demos and testing)")
+	simulateErrors  = flag.Float64("simulate-errors", 0, "with -simulate, the chance of each character being typed wrong, from 0 to 1")
+	simulateSeed    = flag.Int64("simulate-seed", 1, "with -simulate, the seed for choosing the texts and the mistakes, so that every run is the same")
+)
+
+// The options left out of the usage message, as they are for demos and testing rather than for playing.
+var hiddenFlags = map[string]bool{"simulate": true, "simulate-errors": true, "simulate-seed": true}
+
+func init() {
+	flag.BoolVar(noSave, "ephemeral", false, "the same as -no-save")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+
+		shown := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+		shown.SetOutput(flag.CommandLine.Output())
+		flag.VisitAll(func(option *flag.Flag) {
+			if !hiddenFlags[option.Name] {
+				shown.Var(option.Value, option.Name, option.Usage)
+				shown.Lookup(option.Name).DefValue = option.DefValue
+			}
+		})
+		shown.PrintDefaults()
+	}
+}
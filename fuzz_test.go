@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/agnivade/levenshtein"
+)
+
+// Checks the invariants that the result of scoring the input typed for the text must hold,
+// whatever the input, the text and the time are.
+func checkInvariants(textToType string, result Result) error {
+	maxScore := *baseScore
+	if result.scoring == "length" {
+		maxScore = pointsPerChar * textLength(textToType)
+	}
+	if result.score < 0 || result.score > maxScore {
+		return fmt.Errorf("score %d is not within 0 and %d", result.score, maxScore)
+	}
+
+	if result.accuracy < 0 || result.accuracy > 100 || math.IsNaN(result.accuracy) {
+		return fmt.Errorf("accuracy %v is not within 0 and 100", result.accuracy)
+	}
+
+	longest := len([]rune(result.input))
+	if length := len([]rune(textToType)); length > longest {
+		longest = length
+	}
+	if result.distance < 0 || result.distance > longest {
+		return fmt.Errorf("distance %d is not within 0 and %d", result.distance, longest)
+	}
+
+	if levenshteinDistance := levenshtein.ComputeDistance(result.input, textToType); result.distance > levenshteinDistance {
+		return fmt.Errorf("distance %d is more than the Levenshtein distance %d", result.distance, levenshteinDistance)
+	}
+
+	if substitutions, insertions, deletions, transpositions := countEdits(result.ops); substitutions+insertions+deletions+transpositions != result.distance {
+		return fmt.Errorf("the edits (%d wrong, %d extra, %d missing, %d swapped) don't add up to the distance %d",
+			substitutions, insertions, deletions, transpositions, result.distance)
+	}
+
+	if result.wpm < 0 || math.IsNaN(result.wpm) || math.IsInf(result.wpm, 0) {
+		return fmt.Errorf("speed %v WPM is negative or not finite", result.wpm)
+	}
+
+	// Everything typed for an empty text is extra.
+	if textToType == "" && result.distance != len([]rune(result.input)) {
+		return fmt.Errorf("distance %d for an empty text is not the length of the input %d", result.distance, len([]rune(result.input)))
+	}
+
+	// An empty text has nothing in it to get wrong.
+	if textToType == "" {
+		if result.accuracy != 100 {
+			return fmt.Errorf("accuracy %v for an empty text is not 100", result.accuracy)
+		}
+	} else if (result.accuracy == 100) != (result.distance == 0) && textLength(textToType) == len([]rune(textToType)) {
+		return fmt.Errorf("accuracy %v doesn't go with distance %d", result.accuracy, result.distance)
+	}
+
+	return nil
+}
+
+// The scoring variants the fuzzed cases are scored with.
+var fuzzScorings = []string{"fixed", "clock", "length"}
+
+// Scores inputs typed for texts in any time, including none and negative ones, with each scoring variant
+// and distance algorithm, and checks that the results hold the invariants. See checkInvariants.
+func FuzzScore(f *testing.F) {
+	// Empty texts and inputs, characters encoded in several bytes, a combining accent and whitespace
+	// are the likeliest to trip up the scoring.
+	seeds := []struct {
+		text, input string
+	}{
+		{"", ""},
+		{"", "abc"},
+		{"abc", ""},
+		{"the", "teh"},
+		{"hello world", "helo wrold"},
+		{"日本語", "本日語"},
+		{"café", "café"},
+		{"\tindented \n", " indented"},
+		{"🙂 ok", "🙂🙂 ok!"},
+	}
+	for _, seed := range seeds {
+		for _, totalTime := range []time.Duration{-time.Second, 0, time.Millisecond, time.Minute} {
+			f.Add(seed.text, seed.input, int64(totalTime), uint8(0), uint8(0))
+		}
+	}
+	f.Add("abcd", "badc", int64(time.Second), uint8(2), uint8(1))
+
+	defer func(scoringWas, metricWas string) {
+		*scoring, *distanceMetric = scoringWas, metricWas
+	}(*scoring, *distanceMetric)
+
+	f.Fuzz(func(t *testing.T, textToType, input string, nanoseconds int64, variant, algorithm uint8) {
+		*scoring = fuzzScorings[int(variant)%len(fuzzScorings)]
+		*distanceMetric = distanceAlgorithms[int(algorithm)%len(distanceAlgorithms)].name
+
+		result := scoreInput(textToType, input, rawInput{}, time.Duration(nanoseconds))
+		if err := checkInvariants(textToType, result); err != nil {
+			t.Errorf("scoring %q typed for %q in %v with %s scoring and %s distance: %v",
+				input, textToType, time.Duration(nanoseconds), *scoring, *distanceMetric, err)
+		}
+	})
+}
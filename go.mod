@@ -1,13 +1,16 @@
-module main
+module github.com/r00ster91/typer
 
-go 1.17
+go 1.18
 
 require (
 	github.com/agnivade/levenshtein v1.1.1
+	github.com/atotto/clipboard v0.1.4
+	golang.org/x/term v0.1.0
 	rsc.io/quote v1.5.2
 )
 
 require (
+	golang.org/x/sys v0.0.0-20210615035016-665e8c7367d1 // indirect
 	golang.org/x/text v0.0.0-20170915032832-14c0d48ead0c // indirect
 	rsc.io/sampler v1.3.0 // indirect
 )
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Whether the keyboard of guided mode was hidden with Ctrl+K. It stays hidden in the following rounds until Ctrl+K is pressed again.
+var keyboardHidden bool
+
+// A key of the keyboard drawn in guided mode.
+type keyCap struct {
+	label string
+	// The characters the key types without and with Shift, which are 0 for keys that don't type any, such as Shift.
+	char, shifted rune
+}
+
+// How far the Space bar is indented, to sit below the middle of the bottom row.
+const spaceBarIndent = 13
+
+// Gets the rows of keys of the layout from the number row down, followed by a row with the Space bar.
+// The rows are nil if there is no such layout.
+func keyboardRows(name string) [][]keyCap {
+	for _, layout := range layouts {
+		if layout.name != name {
+			continue
+		}
+
+		rows := [][]keyCap{
+			{{"`", '`', '~'}},
+			{{"Tab", '\t', 0}},
+			{{"Caps", 0, 0}},
+			{{"Shift", 0, 0}},
+		}
+		for i, row := range layout.rows {
+			shifted := []rune(layout.shifted[i])
+			for column, r := range []rune(row) {
+				rows[i] = append(rows[i], keyCap{string(r), r, shifted[column]})
+			}
+		}
+		rows[0] = append(rows[0], keyCap{"Bksp", keyBackspace, 0})
+		rows[2] = append(rows[2], keyCap{"Enter", '\n', 0})
+		rows[3] = append(rows[3], keyCap{"Shift", 0, 0})
+		return append(rows, []keyCap{{"     Space     ", ' ', 0}})
+	}
+	return nil
+}
+
+// Draws the keyboard of the layout, one line per row of keys, with the keys to press for the next key highlighted.
+// The next key is a character or keyBackspace, or 0 if there is none.
+//
+// Each key is drawn as the character it types without Shift, or as its name for keys such as Tab,
+// with the rows staggered as on a keyboard:
+//
+//	`  1  2  3  4  5  6  7  8  9  0  -  =  Bksp
+//	Tab  q  w  e  r  t  y  u  i  o  p  [  ]  \
+//	Caps  a  s  d  f  g  h  j  k  l  ;  '  Enter
+//	Shift  z  x  c  v  b  n  m  ,  .  /  Shift
+//	                  Space
+//
+// The keys to press are put in brackets as well as highlighted, so they stand out without colors, too.
+// A character typed with Shift also highlights the Shift key of the other hand, as in touch typing.
+// If the character isn't on the layout, a line below the keyboard names it instead.
+func renderKeyboard(name string, next rune) string {
+	rows := keyboardRows(name)
+	fingerOf := fingerMap(name)
+	leftShift, rightShift := &rows[3][0], &rows[3][len(rows[3])-1]
+
+	pressed := make(map[*keyCap]bool)
+	for i := range rows {
+		for j := range rows[i] {
+			key := &rows[i][j]
+			switch {
+			case next == 0:
+			case key.char == next:
+				pressed[key] = true
+			case key.shifted == next:
+				pressed[key] = true
+				// The fingers of the left hand are the first half of those before the thumbs.
+				if fingerOf[next] < thumbs/2 {
+					pressed[rightShift] = true
+				} else {
+					pressed[leftShift] = true
+				}
+			}
+		}
+	}
+
+	var lines []string
+	for i, row := range rows {
+		var line strings.Builder
+		line.WriteString(strings.Repeat(" ", len(prefix)))
+		if i == len(rows)-1 {
+			line.WriteString(strings.Repeat(" ", spaceBarIndent))
+		}
+		for j := range row {
+			if key := &row[j]; pressed[key] {
+				line.WriteString(paint(roleHighlight, "["+key.label+"]"))
+			} else {
+				line.WriteString(" " + key.label + " ")
+			}
+		}
+		lines = append(lines, line.String())
+	}
+	if next != 0 && len(pressed) == 0 {
+		lines = append(lines, fmt.Sprintf("%sNext: %s, which isn't on the %s keyboard", strings.Repeat(" ", len(prefix)), keyName(next), name))
+	}
+
+	// The lines are drawn in raw mode, where a line feed doesn't return the cursor to the start of the line.
+	return strings.Join(lines, "\r\n")
+}
+
+// Gets the key to press next in guided mode: Backspace after a mistake, otherwise the next character of the target,
+// or 0 once all of it is typed.
+func nextKey(input, target []rune) rune {
+	typed := typedCorrectly(input, target)
+	switch {
+	case typed < len(input):
+		return keyBackspace
+	case typed < len(target):
+		return target[typed]
+	}
+	return 0
+}
+
+// Gets the keyboard shown below the text in guided mode, which is empty if it is hidden or lines can't be redrawn.
+func guidedKeyboard(input, target []rune) string {
+	if *mode != "guided" || keyboardHidden || !canRedrawLines() {
+		return ""
+	}
+
+	layout := *keyboardLayout
+	if layout == "" {
+		layout = defaultLayout
+	}
+	return renderKeyboard(layout, nextKey(input, target))
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// The modes that can be played with -headless, which neither need raw mode nor print anything between rounds.
+var headlessModes = map[string]bool{
+	"normal": true, "reverse": true, "numbers": true, "symbols": true, "ngrams": true,
+}
+
+// The response to "next": the text to type and where it is from, if known.
+type headlessText struct {
+	Text   string `json:"text"`
+	Source string `json:"source,omitempty"`
+}
+
+// The response to "submit": the result of the round.
+type headlessResult struct {
+	Seconds        float64 `json:"seconds"`
+	Distance       int     `json:"distance"`
+	Score          int     `json:"score"`
+	WPM            float64 `json:"wpm"`
+	Accuracy       float64 `json:"accuracy"`
+	Substitutions  int     `json:"substitutions"`
+	Insertions     int     `json:"insertions"`
+	Deletions      int     `json:"deletions"`
+	Transpositions int     `json:"transpositions"`
+	// Whether the round counts toward the stats and highscores. See Result.Counts.
+	Counts       bool `json:"counts"`
+	NewHighscore bool `json:"new_highscore"`
+}
+
+// The response to "stats": the rounds of the session so far that count toward the stats.
+type headlessStats struct {
+	Rounds   int     `json:"rounds"`
+	Score    int     `json:"score"`
+	WPM      float64 `json:"wpm"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+// The response to a command that failed.
+type headlessError struct {
+	Error string `json:"error"`
+}
+
+// The response to "quit", once the scores were saved.
+type headlessDone struct {
+	Done bool `json:"done"`
+}
+
+// Where the responses of -headless go. Everything else printed goes to stderr instead, so that the output is only responses.
+var headlessOutput io.Writer = os.Stdout
+
+// Sends everything but the responses of -headless, such as the messages printed on starting, to stderr.
+func separateHeadlessOutput() {
+	headlessOutput = os.Stdout
+	os.Stdout = os.Stderr
+}
+
+// Exits if the game can't be played with -headless in the current mode or with the given options.
+func validateHeadless() {
+	if !*headless {
+		return
+	}
+	if !headlessModes[*mode] {
+		fmt.Println(*mode, "mode can't be played with -headless")
+		os.Exit(1)
+	}
+	if *rawMode || *players != "" || simulating() {
+		fmt.Println("-headless can't be used with raw mode, -players or -simulate")
+		os.Exit(1)
+	}
+}
+
+// Plays with another program over a line-based protocol instead of with a player: the program sends one command per line
+// and gets one JSON object per line in response. See the README for the commands.
+// The game ends, saving the scores, on "quit" or at the end of the input.
+func runHeadless() {
+	encoder := json.NewEncoder(headlessOutput)
+	encoder.SetEscapeHTML(false)
+	respond := func(response interface{}) {
+		encoder.Encode(response)
+	}
+
+	var text string
+	var startedAt = now()
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			saveHeadless(respond, false)
+			return
+		}
+
+		command := strings.TrimRight(line, "\r\n")
+		argument := ""
+		if i := strings.IndexByte(command, ' '); i >= 0 {
+			command, argument = command[:i], command[i+1:]
+		}
+
+		switch command {
+		case "next":
+			if !generatesTexts() && len(pool()) == 0 {
+				respond(headlessError{"there are no texts to type"})
+				continue
+			}
+			entry := nextText()
+			text = entry.text
+			startedAt = now()
+			respond(headlessText{text, entry.source.name})
+		case "submit", "submit-json":
+			if text == "" {
+				respond(headlessError{`there is no text to submit for; send "next" first`})
+				continue
+			}
+			input := argument
+			// An input with line breaks is sent as a JSON string with a command of its own, so that an input that only looks like one is taken as it is.
+			if command == "submit-json" {
+				if err := json.Unmarshal([]byte(argument), &input); err != nil {
+					respond(headlessError{"the input is not a valid JSON string: " + err.Error()})
+					continue
+				}
+			}
+			respond(submitHeadless(text, input, now().Sub(startedAt)))
+			text = ""
+		case "stats":
+			summary := summarizeSession()
+			respond(headlessStats{summary.Rounds, session.score, summary.WPM, summary.Accuracy})
+		case "quit":
+			saveHeadless(respond, true)
+			return
+		default:
+			respond(headlessError{fmt.Sprintf("unknown command %q; the commands are next, submit <input>, submit-json <input>, stats and quit", command)})
+		}
+	}
+}
+
+// Scores the input typed for the text in the given time and keeps the result like a round played by a player.
+func submitHeadless(text, input string, totalTime time.Duration) headlessResult {
+	result := scoreInput(text, input, rawInput{}, totalTime)
+	scores.RecordMisses(result.ops)
+
+	newHighscore := false
+	if keepsRecords(text) && result.Counts() {
+		record, exists := scores.Records[text]
+		if !exists {
+			record = &Record{}
+			scores.Records[text] = record
+		}
+		newHighscore = exists && result.score > record.highscore(result.algorithm) && !unverified(result)
+		scores.AddAttempt(text, result, now())
+	}
+
+	session.rounds++
+	if result.Counts() {
+		session.results = append(session.results, result)
+		session.score += result.score
+		scores.RecordKeys(result.input)
+	}
+	if isPerfect(result) {
+		session.perfectStreak++
+	} else {
+		session.perfectStreak = 0
+	}
+
+	substitutions, insertions, deletions, transpositions := countEdits(result.ops)
+	return headlessResult{
+		Seconds:        result.totalTime.Seconds(),
+		Distance:       result.distance,
+		Score:          result.score,
+		WPM:            result.wpm,
+		Accuracy:       result.accuracy,
+		Substitutions:  substitutions,
+		Insertions:     insertions,
+		Deletions:      deletions,
+		Transpositions: transpositions,
+		Counts:         result.Counts(),
+		NewHighscore:   newHighscore,
+	}
+}
+
+// Saves the scores unless -no-save is given, responding with an error if that fails or, if asked for, that the game is done.
+func saveHeadless(respond func(interface{}), done bool) {
+	if !*noSave {
+		if err := scores.Save(); err != nil {
+			respond(headlessError{"failed to save the scores: " + err.Error()})
+			return
+		}
+	}
+	if done {
+		respond(headlessDone{true})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// The fewest rounds for which the speeds of a session are drawn as a histogram rather than listed.
+const minHistogramRounds = 5
+
+// The most rows a histogram has.
+const maxHistogramRows = 8
+
+// The longest bar of a histogram in characters, so that it fits into the terminal however many rounds were played.
+const histogramWidth = 40
+
+// The widths in WPM that the ranges of a histogram may have, multiplied by any power of ten.
+// The smallest one that needs no more than maxHistogramRows is used.
+var histogramSteps = []float64{1, 2, 5}
+
+// Prints how the speeds of the rounds of the session are spread as a histogram,
+// with a row for each range of speeds and a bar as long as the number of rounds in it.
+// With too few rounds for that, the speeds are listed instead.
+func printWPMHistogram(results []Result) {
+	if len(results) < minHistogramRounds {
+		var listed []string
+		for _, result := range results {
+			listed = append(listed, fmt.Sprintf("%.1f", result.wpm))
+		}
+		fmt.Println("Speeds:", strings.Join(listed, ", "), "WPM")
+		return
+	}
+
+	lowest, highest := results[0].wpm, results[0].wpm
+	for _, result := range results {
+		lowest = math.Min(lowest, result.wpm)
+		highest = math.Max(highest, result.wpm)
+	}
+
+	var step float64
+	for magnitude := 1.0; step == 0; magnitude *= 10 {
+		for _, candidate := range histogramSteps {
+			if math.Floor(highest/(candidate*magnitude))-math.Floor(lowest/(candidate*magnitude)) < maxHistogramRows {
+				step = candidate * magnitude
+				break
+			}
+		}
+	}
+	first := math.Floor(lowest / step)
+	counts := make([]int, int(math.Floor(highest/step)-first)+1)
+	for _, result := range results {
+		counts[int(math.Floor(result.wpm/step)-first)]++
+	}
+
+	most := 0
+	for _, count := range counts {
+		if count > most {
+			most = count
+		}
+	}
+
+	fmt.Println("Speeds:")
+	labelWidth := len(fmt.Sprintf("%.0f", (first+float64(len(counts)))*step))
+	for i, count := range counts {
+		bar := count
+		if most > histogramWidth {
+			bar = int(math.Round(float64(count) / float64(most) * histogramWidth))
+		}
+		from := (first + float64(i)) * step
+		fmt.Printf("%s%*.0f-%-*.0f WPM %s %d\n", prefix, labelWidth, from, labelWidth, from+step, strings.Repeat("█", bar), count)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync"
+)
+
+var reader = bufio.NewReader(os.Stdin)
+
+// A key pressed by the player, or the error that ended the input.
+type keyPress struct {
+	r   rune
+	err error
+}
+
+var (
+	keyPressChannel chan keyPress
+	startReading    sync.Once
+)
+
+// Gets the channel the keys pressed by the player are sent to.
+//
+// The input is read in the background so that waiting for a key can be combined with waiting for other things,
+// such as a timer. In line mode, the terminal only passes on a line once Enter is pressed.
+// Escape sequences such as the ones sent by the arrow keys are discarded.
+func keyPresses() chan keyPress {
+	startReading.Do(func() {
+		keyPressChannel = make(chan keyPress)
+		go func() {
+			for {
+				r, _, err := reader.ReadRune()
+				if err == nil && r == keyEscape {
+					skipEscapeSequence()
+					continue
+				}
+
+				keyPressChannel <- keyPress{r, err}
+
+				if err != nil {
+					return
+				}
+			}
+		}()
+	})
+	return keyPressChannel
+}
+
+// Waits for the next key pressed by the player.
+func readKey() (rune, error) {
+	key := <-keyPresses()
+	return key.r, key.err
+}
+
+// Ends the game because the input could not be read.
+// This usually means the input ended, such as when Ctrl+D is pressed,
+// but if the terminal went away, the scores are saved without printing anything to it.
+func handleInputError(err error) {
+	if err != io.EOF || terminalGone() {
+		hangUp()
+	}
+	quit()
+}
+
+// Saves the scores and exits without printing anything, for when the terminal went away.
+func hangUp() {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err == nil {
+		os.Stdout = devNull
+	}
+	quit()
+}
+
+// Discards the rest of an escape sequence such as the one sent by an arrow key.
+func skipEscapeSequence() {
+	if reader.Buffered() == 0 {
+		return
+	}
+
+	r, _, _ := reader.ReadRune()
+	if r != '[' && r != 'O' {
+		return
+	}
+
+	for reader.Buffered() > 0 {
+		r, _, _ = reader.ReadRune()
+		if r >= 0x40 && r <= 0x7e { // final byte of the sequence
+			return
+		}
+	}
+}
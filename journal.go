@@ -0,0 +1,31 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Gets a short hash identifying the text that stays the same across runs.
+func hashText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Appends a line about the round to the journal file.
+// Each line holds the tab-separated time, text hash, speed, accuracy, score, mode, scoring variant and distance algorithm.
+func logRound(path string, result Result, text string, at time.Time) (err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "%s\t%s\t%.1f\t%.1f\t%d\t%s\t%s\t%s\n",
+		at.Format(time.RFC3339), hashText(text), result.wpm, result.accuracy, result.score, *mode, result.scoring, result.algorithm)
+
+	return
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// An entry of a leaderboard file shared by several players.
+// The file holds one entry per line, encoded as JSON.
+type LeaderboardEntry struct {
+	Name  string `json:"name"`
+	Text  string `json:"text"`
+	Score int    `json:"score"`
+}
+
+// How long to wait for another player to release the leaderboard file.
+const leaderboardLockTimeout = 5 * time.Second
+
+// Locks the leaderboard file so that no other player can write to it at the same time.
+// A separate lock file is used because it works on shared drives too.
+func lockLeaderboard(path string) (unlock func(), err error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(leaderboardLockTimeout)
+
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			lockFile.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("the leaderboard is locked; remove %s if no one else is playing", lockPath)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Adds the entry to the leaderboard file and reads in all entries for the same text, including the new one.
+func appendToLeaderboard(path string, entry LeaderboardEntry) (entries []LeaderboardEntry, err error) {
+	unlock, err := lockLeaderboard(path)
+
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var existing LeaderboardEntry
+		if json.Unmarshal(scanner.Bytes(), &existing) != nil {
+			continue // skip corrupt lines rather than failing entirely
+		}
+		if existing.Text == entry.Text {
+			entries = append(entries, existing)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return
+	}
+
+	line, err := json.Marshal(entry)
+
+	if err != nil {
+		return
+	}
+
+	_, err = file.Write(append(line, '\n'))
+
+	entries = append(entries, entry)
+
+	return
+}
+
+// Gets the player's name for the leaderboard.
+func playerName() string {
+	if *name != "" {
+		return *name
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return "anonymous"
+}
+
+// Adds the result to the leaderboard and prints where the player ranks for the text.
+func updateLeaderboard(result Result, text string) {
+	entry := LeaderboardEntry{playerName(), text, result.score}
+	entries, err := appendToLeaderboard(*leaderboard, entry)
+
+	if err != nil {
+		fmt.Println("Failed to update the leaderboard:", err)
+		return
+	}
+
+	rank := 1
+	for _, other := range entries {
+		if other.Score > entry.Score {
+			rank++
+		}
+	}
+
+	fmt.Printf("Leaderboard rank for this text: #%d of %d\n", rank, len(entries))
+}
@@ -1,38 +1,99 @@
 package main
 
 import (
-	"bufio"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"math/rand"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
-	"github.com/agnivade/levenshtein"
+	"golang.org/x/term"
 	"rsc.io/quote"
 )
 
 // The source of texts to be typed.
-var text = []string{
-	quote.Glass(), quote.Go(), quote.Opt(), quote.Hello(),
-	// Source for the following sentences: https://tour.golang.org/
-	"Go provides concurrency features as part of the core language.",
-	"A function can take zero or more arguments.",
-	"A function can return any number of results.",
-	"A struct is a collection of fields.",
-	"Struct fields are accessed using a dot.",
-	"Go's return values may be named.",
-	"A var statement can be at package or function level.",
-	"A map maps keys to values.",
+var text = []textEntry{
+	{text: quote.Glass(), source: attribution{"I Can Eat Glass", "http://www.oocities.org/nodotus/hbglass.html"}},
+	{text: quote.Go(), source: attribution{"Go Proverbs", "https://go-proverbs.github.io/"}},
+	{text: quote.Opt(), source: attribution{"Ken Thompson", ""}},
+	{text: quote.Hello()},
+	{text: "Go provides concurrency features as part of the core language.", source: goTour},
+	{text: "A function can take zero or more arguments.", source: goTour},
+	{text: "A function can return any number of results.", source: goTour},
+	{text: "A struct is a collection of fields.", source: goTour},
+	{text: "Struct fields are accessed using a dot.", source: goTour},
+	{text: "Go's return values may be named.", source: goTour},
+	{text: "A var statement can be at package or function level.", source: goTour},
+	{text: "A map maps keys to values.", source: goTour},
+}
+
+// Reports whether the text is one of the texts to be typed.
+func isKnownText(str string) bool {
+	_, ok := findText(str)
+	return ok
+}
+
+// Gets the entry of the text among the texts to be typed.
+func findText(str string) (entry textEntry, ok bool) {
+	for _, entry = range text {
+		if entry.text == str {
+			return entry, true
+		}
+	}
+	return textEntry{}, false
+}
+
+// Gets the entry of the text among the texts to be typed, or an entry of just the text if it isn't one of them.
+func entryOf(str string) textEntry {
+	if entry, ok := findText(str); ok {
+		return entry
+	}
+	return textEntry{text: str}
 }
 
 type Result struct {
 	totalTime time.Duration
 	distance  int
 	score     int
+	// How many times a character was erased to correct a mistake.
+	// This is only counted in raw mode.
+	corrections int
+	// How many wrong keys were pressed with -stop-on-error.
+	wrongKeys int
+	wpm       float64
+	accuracy  float64
+	// The edit operations turning the input into the text.
+	ops []editOp
+	// The scoring variant the score was calculated with.
+	scoring string
+	// The input as it was compared against the text.
+	input string
+	// How far off the beat of -metronome the keys were pressed on average, and the rhythm score that follows.
+	offBeat time.Duration
+	rhythm  float64
+	// How many repeated keys were ignored with -filter-chatter.
+	chatter int
+	// The time taken for each word typed. This is only measured in raw mode.
+	words []wordTime
+	// Whether the input was cut off at maxInputLength.
+	truncated bool
+	// How many times a wrong character was typed at the start of the input in raw mode.
+	falseStarts int
+	// Whether the clock went backwards during the round, such as when the system time was changed,
+	// in which case the time and everything calculated from it are meaningless.
+	clockSkewed bool
+	// How many keys were pressed in total, including corrections. This is only counted in raw mode.
+	keystrokes int
+	// How far behind the dictation the words were typed on average in dictation mode.
+	dictationLag time.Duration
+	// The algorithm the distance was measured with.
+	algorithm string
 }
 
 // Pluralizes the string if required.
@@ -44,12 +105,45 @@ func pluralize(str string, n int) string {
 	}
 }
 
+// Reports whether the round counts toward the stats and highscores,
+// which it doesn't in learn mode, if its time couldn't be measured or if its accuracy is below -min-accuracy.
+func (result Result) Counts() bool {
+	return *mode != "learn" && !result.clockSkewed && result.accuracy >= *minAccuracy
+}
+
 // Prints the result, including time taken to type the text, distance and score.
 func (result Result) Print(text string) {
-	fmt.Println("Finished in", result.totalTime.String()+"!")
+	if result.clockSkewed {
+		fmt.Println("Finished, but the clock went backwards during the round, so its time couldn't be measured")
+	} else {
+		fmt.Println(paint(roleInfo, "Finished in "+result.totalTime.String()+"!"))
+	}
+	fmt.Printf("Speed: %.1f WPM, accuracy: %.1f%%\n", result.wpm, result.accuracy)
+
+	if result.truncated {
+		fmt.Println("Only the first", len([]rune(result.input)), "characters you typed were scored")
+	}
 
-	if result.distance != 0 {
-		fmt.Println("Off by", result.distance, pluralize("character", result.distance))
+	if result.distance != 0 || result.wrongKeys != 0 {
+		if result.distance != 0 {
+			fmt.Println(paint(roleIncorrect, fmt.Sprint("Off by ", result.distance, " ", pluralize("character", result.distance))))
+
+			// With -no-echo, what was typed is only revealed now.
+			if *verbose || *noEcho {
+				printDiff(result.ops)
+			}
+		}
+
+		if result.distance != 0 && *showTarget && !*quiet {
+			fmt.Println("The text was:")
+			fmt.Println(prefix + expandTabs(strings.ReplaceAll(text, "\n", "\n"+prefix)))
+			fmt.Println("You typed:")
+			fmt.Println(prefix + expandTabs(strings.ReplaceAll(result.input, "\n", "\n"+prefix)))
+		}
+
+		if result.wrongKeys != 0 {
+			fmt.Println("Pressed", result.wrongKeys, pluralize("wrong key", result.wrongKeys))
+		}
 
 		if result.score == 0 {
 			fmt.Println("No score")
@@ -57,184 +151,1114 @@ func (result Result) Print(text string) {
 			fmt.Println("Score:", result.score)
 		}
 	} else {
-		fmt.Println("Perfect Score -", result.score)
+		fmt.Println(paint(roleCorrect, fmt.Sprint("Perfect Score - ", result.score)))
+	}
+
+	if *paceWPM > 0 {
+		if result.wpm >= float64(*paceWPM) {
+			fmt.Println("You kept pace with", *paceWPM, "WPM!")
+		} else {
+			fmt.Println("You fell behind the pace of", *paceWPM, "WPM")
+		}
 	}
 
-	previousScore := scores[text]
-	if result.score > previousScore {
-		fmt.Println("NEW HIGHSCORE!")
-		scores[text] = result.score
+	if *mode == "dictation" {
+		fmt.Println("You typed", result.dictationLag.Round(10*time.Millisecond), "behind the dictation on average")
 	}
-}
 
-type Scores map[string]int
+	if metronomeOn() {
+		fmt.Printf("Rhythm score: %.0f (keys were off the beat by %v on average)\n",
+			result.rhythm, result.offBeat.Round(time.Millisecond))
+	}
 
-// Holds scores corresponding to the respective text.
-// This is saved locally and loaded on start.
-var scores Scores
+	if *rawMode {
+		fmt.Println("Corrections:", result.corrections)
+		// The clean typing score also counts every corrected mistake.
+		fmt.Println("Clean typing score:", getScore(result.distance+result.corrections))
 
-// Saves the scores to a local file.
-func (scores Scores) Save() (err error) {
-	scoresJson, err := json.Marshal(scores)
+		if *filterChatter {
+			fmt.Println("Ignored", result.chatter, pluralize("repeated key", result.chatter))
+		}
 
-	if err != nil {
-		return
+		if *fairStart {
+			fmt.Println("False starts:", result.falseStarts)
+		}
+
+		if *showKPS && result.totalTime > 0 {
+			fmt.Printf("Keys per second: %.1f (%d %s)\n",
+				float64(result.keystrokes)/result.totalTime.Seconds(), result.keystrokes, pluralize("key", result.keystrokes))
+		}
+
+		printSlowestWords(result.words)
 	}
 
-	perm := os.FileMode(0644) // Read write permissions
-	err = ioutil.WriteFile("scores.json", scoresJson, perm)
+	if practicesChars() {
+		printCharAccuracy(result.ops)
+	}
 
-	return
-}
+	if *mode == "ngrams" {
+		printNgramStats(result.ops, result.words)
+	}
 
-// Loads the scores from a local file.
-func (scores Scores) Load() (err error) {
-	scoresJson, err := ioutil.ReadFile("scores.json")
+	if *mode == "learn" {
+		fmt.Println("Rounds in learn mode don't count toward your stats")
+		return
+	}
 
-	if err != nil {
-		return nil
+	if result.clockSkewed {
+		fmt.Println("This round doesn't count toward your stats because its time couldn't be measured")
+		return
 	}
 
-	json.Unmarshal(scoresJson, &scores)
+	if !result.Counts() {
+		fmt.Printf("This round doesn't count toward your stats because its accuracy is below %.1f%%\n", *minAccuracy)
+		return
+	}
 
-	return
+	if !keepsRecords(text) {
+		return
+	}
+
+	record := scores.Records[text]
+	if result.score > record.highscore(result.algorithm) {
+		if unverified(result) {
+			fmt.Printf("Unverified highscore: it only counts during a streak of %d perfect %s, and yours is at %d\n",
+				*verifiedStreak, pluralize("round", *verifiedStreak), streakWith(result))
+		} else {
+			fmt.Println("NEW HIGHSCORE!")
+			record.setHighscore(result.algorithm, result.score)
+		}
+	}
 }
 
 // Determines whether the first text has been typed.
 var firstRun = true
 
-func handleCtrlC() {
-	if scores.Save() != nil {
-		fmt.Println("Failed to save scores")
+// Determines whether the player has never played before.
+// Unlike firstRun, this is based on whether any scores were saved.
+var newPlayer bool
+
+// Introduces a new player to the game.
+func onboard() {
+	fmt.Println("Welcome to typer!")
+	fmt.Println()
+	fmt.Println("Each round you are shown a text to type as quickly and accurately as you can.")
+	fmt.Println("Type it right over the text and press Enter when you are done.")
+	fmt.Println("Every character you are off by costs", *penalty, "of the", *baseScore, "points a round is worth.")
+	fmt.Println("Your highscore for each text is kept, so you can try to beat it later.")
+	fmt.Println("Press Ctrl+C at any time to save your scores and exit.")
+	fmt.Println()
+	fmt.Println("Press Enter to start")
+	readLine()
+}
+
+// Held while quitting so that the scores are only saved once, even if quitting is triggered twice at the same time.
+var quitting sync.Mutex
+
+// Ends the session, saves the scores and exits.
+// This happens when the player presses Ctrl+C, has played the rounds given with -count, has reached the score given with -until-score
+// or is done with a sprint.
+func quit() {
+	quitting.Lock()
+
+	exitRawMode()
+
+	if multiplaying() {
+		announceSessionWinner()
+	} else {
+		endSession()
+		saveSession(true)
+	}
+
+	if *noSave {
+		// Nothing is written with -no-save.
+	} else if err := saveScores(); err != nil {
+		fmt.Println("Failed to save scores:", err)
 	}
 
 	fmt.Println("See you later!")
 	os.Exit(0)
 }
 
+// Loads the scores, setting them aside to start afresh if they are corrupt and exiting if they can't be read.
+func loadScores() {
+	err := scores.Load()
+
+	if errors.Is(err, ErrScoresCorrupt) {
+		path, renameErr := setAsideScores()
+		if renameErr != nil {
+			fmt.Println("Failed to load scores:", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("Your scores were corrupt, so they were moved to", path, "and you start afresh")
+		scores = newScores()
+	} else if err != nil {
+		fmt.Println("Failed to load scores:", err)
+		os.Exit(1)
+	}
+}
+
+// Exits if any options are invalid.
+func validateFlags() {
+	if *profile != "" && !validProfileName.MatchString(*profile) {
+		fmt.Println("Invalid profile name:", *profile)
+		fmt.Println("Use up to 64 letters, digits, - and _")
+		os.Exit(1)
+	}
+
+	if *scoring != "fixed" && *scoring != "clock" && *scoring != "length" {
+		fmt.Println("Unknown scoring variant:", *scoring)
+		os.Exit(1)
+	}
+
+	if *submit != "enter" && *submit != "ctrl-d" && *submit != "double-enter" {
+		fmt.Println("Unknown submit key:", *submit)
+		os.Exit(1)
+	}
+
+	if *export != "" && *export != "md" {
+		fmt.Println("Unknown export format:", *export)
+		os.Exit(1)
+	}
+
+	if *order != "random" && *order != "sequential" && *order != "shuffle" {
+		fmt.Println("Unknown order:", *order)
+		os.Exit(1)
+	}
+
+	if *noRepeatWindow < 0 {
+		fmt.Println("The no-repeat window can't be negative")
+		os.Exit(1)
+	}
+
+	if *balanced && *order != "random" {
+		fmt.Println("-balanced only works with -order random")
+		os.Exit(1)
+	}
+
+	if *idleAction != "save" && *idleAction != "exit" {
+		fmt.Println("Unknown idle action:", *idleAction)
+		os.Exit(1)
+	}
+
+	if *sprintStartWPM <= 0 || *sprintStep < 0 {
+		fmt.Println("The speed of a sprint must be positive and can't go down")
+		os.Exit(1)
+	}
+
+	if *runs < 2 {
+		fmt.Println("At least 2 runs are needed to tell how consistent they are")
+		os.Exit(1)
+	}
+
+	if *distractors < 0 || *distractors > 1 {
+		fmt.Println("The chance of a distractor must be from 0 to 1")
+		os.Exit(1)
+	}
+
+	if *untilScore < 0 {
+		fmt.Println("The score to reach can't be negative")
+		os.Exit(1)
+	}
+
+	validatePlayers()
+
+	if *breakReminder < 0 || *breakRounds < 0 {
+		fmt.Println("The time and number of rounds after which to take a break can't be negative")
+		os.Exit(1)
+	}
+
+	if *verifiedStreak < 0 {
+		fmt.Println("The streak a highscore has to be made during can't be negative")
+		os.Exit(1)
+	}
+
+	if *simulateWPM < 0 || *simulateErrors < 0 || *simulateErrors > 1 {
+		fmt.Println("The simulated speed can't be negative and the chance of a mistake must be from 0 to 1")
+		os.Exit(1)
+	}
+
+	if *metronome < 0 || *metronome > 0 && beatInterval() < minBeatInterval {
+		fmt.Println("The metronome can't be negative or ring more than", int64(time.Minute/minBeatInterval), "times a minute")
+		os.Exit(1)
+	}
+
+	if *dictationWPM <= 0 || wordInterval() < minWordInterval {
+		fmt.Println("The dictation speed must be positive and at most", int64(time.Minute/minWordInterval), "WPM")
+		os.Exit(1)
+	}
+
+	if *charCount <= 0 {
+		fmt.Println("The number of characters must be positive")
+		os.Exit(1)
+	}
+
+	if *ngramLength != 0 && (*ngramLength < 2 || *ngramLength > 4) {
+		fmt.Println("The length of the letter sequences must be from 2 to 4")
+		os.Exit(1)
+	}
+
+	if !validNgramSet() {
+		fmt.Println("The letter sequences to drill can't be empty or contain whitespace")
+		os.Exit(1)
+	}
+
+	if *milestoneStep < 0 || !validMilestoneList() {
+		fmt.Println("The milestones must be positive whole numbers of WPM")
+		os.Exit(1)
+	}
+
+	if *baseScore <= 0 || *penalty < 0 {
+		fmt.Println("The base score must be positive and the penalty can't be negative")
+		os.Exit(1)
+	}
+
+	if !isValidMode(*mode) {
+		fmt.Println("Unknown mode:", *mode)
+		os.Exit(1)
+	}
+
+	if *textsFile != "" && *textsURL != "" {
+		fmt.Println("Texts can't be taken from both a file and a URL")
+		os.Exit(1)
+	}
+
+	if (*saveSessionTo != "" || *loadSessionFrom != "") && (*players != "" || *headless || simulating()) {
+		fmt.Println("-save-session and -load-session can't be used with -players, -headless or -simulate")
+		os.Exit(1)
+	}
+
+	if *keyboardLayout != "" && fingerMap(*keyboardLayout) == nil {
+		fmt.Println("Unknown keyboard layout:", *keyboardLayout)
+		os.Exit(1)
+	}
+
+	if distanceFunc(*distanceMetric) == nil {
+		fmt.Println("Unknown distance algorithm:", *distanceMetric)
+		os.Exit(1)
+	}
+
+	if themeColors(*theme) == nil {
+		fmt.Println("Unknown theme:", *theme)
+		os.Exit(1)
+	}
+}
+
+// Replaces the built-in texts with the ones to be typed according to the options, leaving out unusable ones.
+// Returns what was left out.
+func loadPool() (report filterReport) {
+	if *fromClipboard {
+		clipboardText, err := clipboardTexts()
+		if err != nil {
+			fmt.Println("Failed to read the clipboard:", err)
+			os.Exit(1)
+		}
+		text = plainTexts(clipboardText)
+	}
+
+	if *mode == "code" {
+		text = plainTexts(snippets)
+	}
+
+	builtin := text
+
+	var fileTexts []textEntry
+	if *textsFile != "" {
+		var err error
+		fileTexts, err = loadTexts(*textsFile)
+		if err != nil {
+			fmt.Println("Failed to load the texts:", err)
+			os.Exit(1)
+		}
+		text = fileTexts
+	}
+
+	if *textsURL != "" {
+		var err error
+		fileTexts, err = loadURL(*textsURL)
+		if err != nil {
+			fmt.Println("Failed to fetch the texts:", err)
+			os.Exit(1)
+		}
+		text = fileTexts
+	}
+
+	if *sourcesSpec != "" {
+		var err error
+		report, err = loadSources(*sourcesSpec, builtin, fileTexts)
+		if err != nil {
+			fmt.Println("Failed to load the sources:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	text, report = filterTexts(text)
+
+	return
+}
+
 func main() {
-	scores = make(Scores)
+	flag.Parse()
+	// The imported settings are the defaults of the options, so the command line is parsed again to override them.
+	// They are left out when importing them anew, so that settings that can't be used don't keep them from being replaced.
+	var configErr error
+	if !importingConfig() {
+		configErr = loadConfig()
+		flag.Parse()
+	}
 
-	err := scores.Load()
+	if *headless {
+		separateHeadlessOutput()
+	}
+	// The warning waits for the options, so that it goes to stderr with -headless.
+	if configErr != nil {
+		warnConfig(configErr)
+	}
+
+	loadSavedSession()
+	validateFlags()
 
-	if err != nil {
-		fmt.Println("Failed to load scores")
-		os.Exit(0)
+	if simulating() {
+		setUpSimulation()
 	}
+	setUpDistractors()
 
-	// Exit gracefully on Ctrl+C
+	switch {
+	case *order == "sequential":
+		chooseText = orderedChooser(false)
+	case *order == "shuffle":
+		chooseText = orderedChooser(true)
+	case *balanced:
+		chooseText = balancedText
+	}
+	if err := useSequenceFromEnv(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	report := loadPool()
+
+	if *check {
+		printCheck(text, report)
+		if len(text) == 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Only the subcommands that type texts need any, so the others run even if the texts can't be used.
+	if len(text) == 0 && (flag.NArg() == 0 || subcommandPlaysTexts(flag.Arg(0))) {
+		explainEmptyPool(report)
+		os.Exit(1)
+	}
+
+	if flag.NArg() > 0 {
+		runSubcommand(flag.Args())
+		return
+	}
+
+	// Guided mode draws the keyboard in raw mode, which needs a terminal. Without one, the texts are typed as in normal mode.
+	guided := *mode == "guided" && term.IsTerminal(int(os.Stdin.Fd()))
+	if *mode == "guided" && !guided {
+		fmt.Println("Guided mode needs a terminal to show the keyboard, so the texts are typed without it")
+	}
+
+	if modeNeedsRawInput() || guided || *stopOnError || *paceWPM > 0 || *liveWPM || *metronome > 0 || *filterChatter || *fairStart || *highlightNext || *showProgress || *submit == "ctrl-d" {
+		*rawMode = true
+	}
+
+	// Unlike the other options of raw mode, -no-echo doesn't turn it on, as hiding the input is only wanted if it is asked for.
+	if *noEcho && !*rawMode {
+		fmt.Println("-no-echo only works in raw mode, so what you type is shown. Use -raw to hide it.")
+		*noEcho = false
+	}
+
+	validateHeadless()
+
+	stdinIsTerminal = term.IsTerminal(int(os.Stdin.Fd()))
+
+	if *rawMode && !stdinIsTerminal {
+		fmt.Println("Raw mode requires a terminal")
+		os.Exit(1)
+	}
+
+	scores = newScores()
+	newPlayer = !scoresFileExists()
+
+	// With -no-save, the scores start out empty and are only kept in memory.
+	if *players != "" {
+		setUpPlayers()
+	} else if !*noSave {
+		loadScores()
+	}
+
+	if *showStats {
+		printStats()
+		return
+	}
+
+	if *showRemaining {
+		printRemaining()
+		return
+	}
+
+	if *export == "md" {
+		exportMarkdown()
+		return
+	}
+
+	if !*noSave {
+		if err := checkWritable(scoresPath()); err != nil {
+			useFallback(err)
+		}
+	}
+
+	if *headless {
+		runHeadless()
+		return
+	}
+
+	// Exit gracefully on Ctrl+C and when the terminal goes away
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	signal.Notify(c, os.Interrupt, syscall.SIGHUP)
 	go func() {
-		for range c {
-			handleCtrlC()
+		for sig := range c {
+			if sig == syscall.SIGHUP {
+				hangUp()
+			}
+			reportLineRound()
+			quit()
 		}
 	}()
 
+	resumeSession()
+	setUpMode()
+
+	if len(sources) > 0 {
+		printSourceMix()
+	}
+
+	if newPlayer {
+		onboard()
+	}
+
 	for {
+		if !generatesTexts() && len(pool()) == 0 {
+			fmt.Println("Your review list is empty. Add texts to it after a round in normal mode.")
+			quit()
+		}
+
+		var entry textEntry
+		if multiplaying() {
+			entry = nextTurnText()
+		} else {
+			entry = nextText()
+		}
+		text := entry.text
+
+		if *showDifficulty {
+			if record, ok := scores.Records[text]; ok && record.Difficulty != "" {
+				fmt.Println("Difficulty:", record.Difficulty, "for you")
+			}
+		}
+
 		fmt.Println("Type the following text as quickly as you can!")
-		countdown()
+		// Learn mode puts no time pressure on the player.
+		if *mode != "learn" {
+			countdown()
+		}
 
-		result, text := play()
+		result := play(text)
 
-		_, exists := scores[text]
-		if !exists {
-			scores[text] = result.score
+		// Whether the round goes into the records of the text.
+		keepRecord := keepsRecords(text) && result.Counts()
+
+		if keepRecord {
+			if _, exists := scores.Records[text]; !exists {
+				scores.Records[text] = &Record{}
+				if !unverified(result) {
+					scores.Records[text].setHighscore(result.algorithm, result.score)
+				}
+			}
 		}
 
 		result.Print(text)
+		reachMilestones(result)
+
+		if entry.source.name != "" && !*quiet {
+			printAttribution(entry)
+		}
+
+		if keepRecord {
+			scores.AddAttempt(text, result, now())
+		}
+
+		if distraction.original != "" {
+			revealDistraction(text, result)
+
+			if keepsRecords(distraction.original) && result.Counts() {
+				recordDistractor(distraction.original, result)
+			}
+		}
+
+		if *journal != "" && !simulating() {
+			if err := logRound(*journal, result, text, now()); err != nil {
+				fmt.Println("Failed to log the round:", err)
+			}
+		}
+
+		if *leaderboard != "" && keepRecord && !simulating() {
+			updateLeaderboard(result, text)
+		}
+
+		if *mode == "drill" {
+			continueDrill(result)
+		}
 
-		if firstRun {
+		if *mode == "consistency" {
+			continueConsistency(result)
+		}
+
+		if *mode == "sprint" {
+			continueSprint(result)
+		}
+
+		if firstRun && newPlayer && !*quiet {
 			fmt.Println("\nKeep playing to see text-specific scores and records!")
 		}
 
-		fmt.Println("\nPress Enter to type another text or Ctrl+C to abort")
-		readLine()
+		session.rounds++
+		if *review {
+			keepForReview(text, result)
+		}
+		if result.Counts() {
+			session.results = append(session.results, result)
+			session.score += result.score
+			scores.RecordKeys(result.input)
+		}
+		if isPerfect(result) {
+			session.perfectStreak++
+		} else {
+			session.perfectStreak = 0
+		}
+
+		if result.Counts() {
+			unlockAchievements(result)
+		}
+		if multiplaying() && finishTurn(result) {
+			continue
+		}
+
+		saveSession(false)
+
+		// Each player's turn counts as a round of the session, so a round of several players takes several.
+		if *count > 0 && session.rounds >= *count*turnsPerRound() || sprint.over {
+			fmt.Println()
+			quit()
+		}
+		if *untilScore > 0 {
+			if session.score >= *untilScore {
+				fmt.Println("\nYou reached your goal of", *untilScore, "with a total score of", session.score)
+				quit()
+			}
+			fmt.Println("Total score:", session.score, "of", *untilScore)
+		}
+
+		if !simulating() {
+			if remindsOfBreaks() {
+				remindOfBreak(result)
+			}
+			promptAfterRound(poolText(entry))
+		}
 
 		firstRun = false
 	}
 }
 
-var lastRandInt int
-var randIntSrc = rand.NewSource(time.Now().UnixNano())
+// These can be replaced to control the game, such as for testing.
+var (
+	// Chooses the next text out of the texts.
+	chooseText = randomText
+	// Gets the current time.
+	now = time.Now
+	// Pauses for the given duration.
+	sleep = time.Sleep
+)
+
+// Makes the texts be chosen in the order given by the TYPER_SEQUENCE environment variable,
+// a comma-separated list of text indices that is repeated once exhausted.
+// Indices out of range wrap around.
+func useSequenceFromEnv() (err error) {
+	value := os.Getenv("TYPER_SEQUENCE")
+	if value == "" {
+		return
+	}
+
+	var sequence []int
+	for _, field := range strings.Split(value, ",") {
+		index, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || index < 0 {
+			return fmt.Errorf("invalid index %q in TYPER_SEQUENCE", field)
+		}
+		sequence = append(sequence, index)
+	}
+
+	chooseText = sequenceChooser(sequence)
+
+	return
+}
+
+// Gets a function choosing the texts at the indices of the sequence, in order.
+func sequenceChooser(sequence []int) func(texts []textEntry) textEntry {
+	position := 0
+	return func(texts []textEntry) textEntry {
+		index := sequence[position%len(sequence)] % len(texts)
+		position++
+		return texts[index]
+	}
+}
+
+// Gets a function choosing the texts one after another, starting over once all of them were chosen.
+// If shuffle is true, the texts are chosen in an order shuffled once rather than in their own order.
+func orderedChooser(shuffle bool) func(texts []textEntry) textEntry {
+	var order []int
+	position := 0
+	return func(texts []textEntry) textEntry {
+		// The order is made anew when the texts change, such as when the review list changes.
+		if len(order) != len(texts) {
+			if shuffle {
+				order = rng.Perm(len(texts))
+			} else {
+				order = make([]int, len(texts))
+				for i := range order {
+					order[i] = i
+				}
+			}
+			position = 0
+		}
+
+		if position == len(order) {
+			fmt.Println("You went through all", len(order), "texts, so they start over")
+			position = 0
+		}
+
+		entry := texts[order[position]]
+		position++
+		return entry
+	}
+}
+
+// Chooses one of the texts at random.
+func randomText(texts []textEntry) textEntry {
+	return texts[getNewRandInt(len(texts))]
+}
+
+// Chooses one of the texts at random, favoring the ones served the least this session
+// so that every text comes up about as often.
+// A text served s times is weighted 1/(1+s)².
+// Unless -allow-repeat is given, the text served last is not chosen again if there is any other.
+func balancedText(texts []textEntry) textEntry {
+	weights := make([]float64, len(texts))
+	total := 0.0
+	for i, entry := range texts {
+		if !*allowRepeat && len(texts) > 1 && entry.text == session.lastServed {
+			continue
+		}
+		served := float64(session.served[entry.text])
+		weights[i] = 1 / ((1 + served) * (1 + served))
+		total += weights[i]
+	}
+
+	pick := rng.Float64() * total
+	chosen := 0
+	for i, weight := range weights {
+		if weight == 0 {
+			continue
+		}
+		chosen = i
+		if pick < weight {
+			break
+		}
+		pick -= weight
+	}
+	return texts[chosen]
+}
+
+// The integers generated most recently, oldest first, which are not generated again for a while.
+// This is a ring buffer of up to -no-repeat-window integers.
+var recentRandInts []int
+var randIntSrc = newCountingSource(time.Now().UnixNano())
 var rng = rand.New(randIntSrc)
 
-// Gets a random integer guaranteed to be different from the previously generated integer.
-// This function has an undefined time complexity and may never terminate.
+// Gets a random integer guaranteed to be different from the -no-repeat-window integers generated before it.
+// The window is made smaller if needed to leave at least one integer to choose.
+// This function has an undefined time complexity.
+// With -allow-repeat, any integer may be generated.
 func getNewRandInt(n int) int {
+	if *allowRepeat {
+		return rng.Intn(n)
+	}
+
+	window := *noRepeatWindow
+	if window > n-1 {
+		window = n - 1
+	}
+	if len(recentRandInts) > window {
+		recentRandInts = recentRandInts[len(recentRandInts)-window:]
+	}
+
 	randInt := rng.Intn(n)
-	for randInt == lastRandInt {
+	for containsInt(recentRandInts, randInt) {
 		randInt = rng.Intn(n)
 	}
-	lastRandInt = randInt
+
+	if window > 0 {
+		if len(recentRandInts) == window {
+			// Drop the oldest integer, reusing the space.
+			copy(recentRandInts, recentRandInts[1:])
+			recentRandInts[window-1] = randInt
+		} else {
+			recentRandInts = append(recentRandInts, randInt)
+		}
+	}
 	return randInt
 }
 
+// Reports whether the integers contain the integer.
+func containsInt(ints []int, i int) bool {
+	for _, other := range ints {
+		if other == i {
+			return true
+		}
+	}
+	return false
+}
+
 // Counts down.
 func countdown() {
 	defer fmt.Println()
 
+	step := time.Millisecond * 750
+	if firstRun {
+		step = time.Millisecond * 1000
+	}
+
+	if *smoothCountdown && canRedrawLines() {
+		animateCountdown(3, step)
+		return
+	}
+
 	countdownStart := 3
 	for countdownStart > 0 {
 		fmt.Println(countdownStart, "...")
-		if firstRun {
-			time.Sleep(time.Millisecond * 1000)
-		} else {
-			time.Sleep(time.Millisecond * 750)
-		}
+		sleep(step)
 		countdownStart--
 	}
 	fmt.Println("Go!")
 }
 
-var reader = bufio.NewReader(os.Stdin)
+// The number of frames per step of the animated countdown.
+const countdownFrames = 10
+
+// Counts down on a single line with a shrinking bar.
+func animateCountdown(countdownStart int, step time.Duration) {
+	total := countdownStart * countdownFrames
+	for frame := total; frame > 0; frame-- {
+		number := (frame + countdownFrames - 1) / countdownFrames
+		bar := strings.Repeat("#", frame) + strings.Repeat(" ", total-frame)
+		fmt.Printf("\r%d [%s]", number, bar)
+		sleep(step / countdownFrames)
+	}
+	fmt.Printf("\r%s\r%s\n", strings.Repeat(" ", total+4), "Go!")
+}
 
 // Reads in a line from the terminal.
 func readLine() string {
-	string, err := reader.ReadString('\n')
+	var line strings.Builder
+	for {
+		r, err := readKey()
+
+		if err != nil {
+			handleInputError(err)
+		}
+
+		line.WriteRune(r)
+		if r == '\n' {
+			return line.String()
+		}
+	}
+}
 
-	if err != nil {
-		// Although there could be other causes, we will just assume here that the user pressed Ctrl+C
-		handleCtrlC()
+// Reads in the input of a round in line mode.
+// With -submit double-enter, lines are read until an empty one so that the input can span several lines.
+func readInput() string {
+	if *submit != "double-enter" {
+		return readLine()
 	}
 
-	return string
+	var input strings.Builder
+	for {
+		line := readLine()
+		if strings.TrimRight(line, "\r\n") == "" {
+			return input.String()
+		}
+		input.WriteString(line)
+	}
+}
+
+// Reads in the input of a round in line mode like readInput, but only for the given time.
+// Once the time runs out, the lines entered until then are taken as the input.
+func readInputWithin(limit time.Duration) string {
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+
+	var input, line strings.Builder
+	for {
+		select {
+		case key := <-keyPresses():
+			if key.err != nil {
+				handleInputError(key.err)
+			}
+
+			line.WriteRune(key.r)
+			if key.r != '\n' {
+				continue
+			}
+			if *submit != "double-enter" {
+				return line.String()
+			}
+			if strings.TrimRight(line.String(), "\r\n") == "" {
+				return input.String()
+			}
+			input.WriteString(line.String())
+			line.Reset()
+		case <-timer.C:
+			return input.String() + line.String()
+		}
+	}
+}
+
+// Reads in a line from the terminal like readLine,
+// but if no key is pressed for -idle, onIdle is called once before waiting on.
+func readLineIdle(onIdle func()) string {
+	if *idleAfter <= 0 {
+		return readLine()
+	}
+
+	timer := time.NewTimer(*idleAfter)
+	defer timer.Stop()
+
+	var line strings.Builder
+	for {
+		select {
+		case key := <-keyPresses():
+			if key.err != nil {
+				handleInputError(key.err)
+			}
+
+			line.WriteRune(key.r)
+			if key.r == '\n' {
+				return line.String()
+			}
+		case <-timer.C:
+			onIdle()
+		}
+	}
 }
 
 const prefix = "> "
 
-// Calculates the score from the distance.
+// Calculates the score from the distance: the base score minus the penalty for each character off.
 func getScore(distance int) int {
-	score := 10 - distance
+	score := *baseScore - distance**penalty
 	if score < 0 {
 		return 0
 	} else {
-		return score * 100
+		return score
+	}
+}
+
+// Calculates the score of the "beat the clock" variant, in which the points
+// a round is worth decay over time from the base score down to a floor and each mistake costs the penalty.
+func getClockScore(distance int, totalTime time.Duration) int {
+	potential := *baseScore - int(*clockDecay*totalTime.Seconds())
+	if potential < *clockFloor {
+		potential = *clockFloor
+	}
+
+	score := potential - distance**penalty
+	if score < 0 {
+		return 0
+	} else {
+		return score
+	}
+}
+
+// The points each correctly typed character is worth when scoring by length.
+const pointsPerChar = 25
+
+// Calculates the score of the length-weighted variant, in which each correctly typed character
+// is worth the same, so that longer texts are worth more.
+func getLengthScore(distance, length int) int {
+	correct := length - distance
+	if correct < 0 {
+		return 0
+	} else {
+		return correct * pointsPerChar
+	}
+}
+
+// Scores a round using the chosen scoring variant.
+func scoreRound(distance, length int, totalTime time.Duration) int {
+	switch *scoring {
+	case "clock":
+		return getClockScore(distance, totalTime)
+	case "length":
+		return getLengthScore(distance, length)
+	default:
+		return getScore(distance)
 	}
 }
 
 // Plays a game round.
-func play() (Result, string) {
-	textToType := text[getNewRandInt(len(text))]
-	fmt.Print(
-		prefix,
-		textToType,
-		"\r", // move the cursor to the start
-		prefix,
-	)
-
-	startTime := time.Now()
-	input := readLine()
-	endTime := time.Now()
+func play(textToType string) Result {
+	// In raw mode, the text is drawn as the input is read.
+	// Right-to-left texts are typed on the line below them rather than over them. See isRightToLeft.
+	if !*rawMode && isRightToLeft(textToType) {
+		fmt.Println(prefix + textToType)
+		fmt.Print(prefix)
+	} else if !*rawMode {
+		fmt.Print(
+			prefix,
+			visibleText("", textToType),
+			"\r", // move the cursor to the start
+			prefix,
+		)
+	}
+
+	var input string
+	var raw rawInput
+
+	startTime := now()
+	if simulating() {
+		raw = simulateTyping(textToType)
+		input = raw.text
+	} else if *rawMode {
+		raw = readRawLine(textToType)
+		input = raw.text
+
+		// With -fair-start, the time it took to start typing the text right doesn't count.
+		if *fairStart && !raw.startedAt.IsZero() {
+			startTime = raw.startedAt
+		}
+	} else {
+		trackLineRound(true)
+		if *mode == "sprint" {
+			input = readInputWithin(sprint.budget)
+		} else {
+			input = readInput()
+		}
+		trackLineRound(false)
+	}
+	endTime := now()
 
 	fmt.Println()
 
-	distance := levenshtein.ComputeDistance(strings.TrimSpace(input), textToType)
-	totalTime := endTime.Sub(startTime)
+	// The time the round was paused doesn't count.
+	totalTime := endTime.Sub(startTime) - raw.paused
+	if *fairStart && !raw.startedAt.IsZero() {
+		totalTime += raw.pausedBeforeStart
+	}
 
-	score := getScore(distance)
+	result := scoreInput(textToType, input, raw, totalTime)
+	scores.RecordMisses(result.ops)
 
-	result := Result{totalTime, distance, score}
+	return result
+}
+
+// How many times as long as the text the input may be, plus maxInputSlack, before it is cut off.
+const (
+	maxInputFactor = 4
+	maxInputSlack  = 100
+)
+
+// Gets the length in characters after which the input for the text is cut off.
+func maxInputLength(textToType string) int {
+	return maxInputFactor*len([]rune(textToType)) + maxInputSlack
+}
+
+// Scores the input typed for the text in the given time.
+// This is everything that happens to the input of a round after it was read in, so it can be run without a player.
+func scoreInput(textToType, input string, raw rawInput, totalTime time.Duration) Result {
+	// The time is measured with the monotonic clock, so this should not happen, but if it does,
+	// a non-positive time would make for a nonsensical speed and score.
+	clockSkewed := totalTime <= 0
+	if clockSkewed {
+		totalTime = 0
+	}
+
+	if *exactWhitespace || *mode == "code" {
+		// Only the line break ending the input is left out.
+		input = strings.TrimSuffix(strings.TrimSuffix(input, "\n"), "\r")
+	} else {
+		input = strings.TrimSpace(input)
+	}
+
+	// A pathologically long input, such as a huge paste, would take too long to compare against the text.
+	truncated := false
+	if limit := maxInputLength(textToType); len([]rune(input)) > limit {
+		input = string([]rune(input)[:limit])
+		truncated = true
+	}
+
+	ops := editOps(input, textToType)
+
+	distance := computeDistance(input, textToType)
 
-	return result, textToType
+	length := textLength(textToType)
+
+	// Wrong keys never make it into the input with -stop-on-error, so they count as mistakes separately.
+	// In learn mode, they don't count at all and neither does the time.
+	var score int
+	if *mode == "learn" {
+		score = getScore(distance)
+	} else {
+		score = scoreRound(distance+raw.wrongKeys, length, totalTime)
+	}
+
+	// Only the characters of the text typed correctly count toward the speed,
+	// so that neither submitting early nor typing garbage is fast.
+	correct := length - distance
+	if correct < 0 {
+		correct = 0
+	}
+	wpm := getWPM(correct, totalTime)
+	accuracy := getAccuracy(distance, length)
+
+	offBeat, rhythm := rhythmOf(raw.keyTimes)
+
+	return Result{
+		totalTime:    totalTime,
+		distance:     distance,
+		score:        score,
+		corrections:  raw.corrections,
+		wrongKeys:    raw.wrongKeys,
+		wpm:          wpm,
+		accuracy:     accuracy,
+		ops:          ops,
+		scoring:      *scoring,
+		input:        input,
+		offBeat:      offBeat,
+		rhythm:       rhythm,
+		chatter:      raw.chatter,
+		words:        raw.words,
+		truncated:    truncated,
+		falseStarts:  raw.falseStarts,
+		clockSkewed:  clockSkewed,
+		keystrokes:   raw.keystrokes,
+		dictationLag: raw.dictationLag,
+		algorithm:    *distanceMetric,
+	}
 }
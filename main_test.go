@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Where the lines typed with typeLine go. The input is read in the background from the first key on,
+// so the reader has to stay the same for all tests.
+var typedLines *io.PipeWriter
+
+// Makes the player type the line and press Enter in line mode.
+func typeLine(line string) {
+	if typedLines == nil {
+		var typed *io.PipeReader
+		typed, typedLines = io.Pipe()
+		reader = bufio.NewReader(typed)
+	}
+	go typedLines.Write([]byte(line + "\n"))
+}
+
+// Makes now return the times one after another, starting at an arbitrary time and then going by the steps,
+// until the test ends.
+func useClock(t *testing.T, steps ...time.Duration) {
+	previous := now
+	t.Cleanup(func() { now = previous })
+
+	current := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	now = func() time.Time {
+		if calls > 0 {
+			current = current.Add(steps[(calls-1)%len(steps)])
+		}
+		calls++
+		return current
+	}
+}
+
+// Starts the test with no scores and restores the scores it had once the test ends.
+func useNewScores(t *testing.T) {
+	previous := scores
+	t.Cleanup(func() { scores = previous })
+	scores = newScores()
+}
+
+// Checks that a huge input, such as a pasted file, is cut off before it is compared against the text,
+// which would otherwise take minutes.
+func TestScoreInputTruncates(t *testing.T) {
+	text := strings.TrimSpace(strings.Repeat("typing ", 150))
+	input := strings.Repeat("x", 1<<20)
+
+	start := time.Now()
+	result := scoreInput(text, input, rawInput{}, time.Minute)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("scoring a megabyte of input took %v", elapsed)
+	}
+
+	if !result.truncated {
+		t.Error("a megabyte of input wasn't truncated")
+	}
+	if length, limit := len([]rune(result.input)), maxInputLength(text); length != limit {
+		t.Errorf("the input was cut off at %d characters, want %d", length, limit)
+	}
+	if result.distance != maxInputLength(text) {
+		t.Errorf("off by %d, want %d", result.distance, maxInputLength(text))
+	}
+}
+
+// Checks that a round timed by a clock going backwards, such as one set back while typing, doesn't count
+// rather than getting a negative speed.
+func TestPlayClockSkewed(t *testing.T) {
+	useNewScores(t)
+	useClock(t, -time.Second)
+
+	typeLine("hello world")
+	result := play("hello world")
+	if !result.clockSkewed {
+		t.Error("a round timed backwards wasn't taken as skewed")
+	}
+	if result.totalTime != 0 || result.wpm != 0 {
+		t.Errorf("a round timed backwards took %v at %v WPM, want no time and speed", result.totalTime, result.wpm)
+	}
+	if result.distance != 0 || result.Counts() {
+		t.Errorf("a round timed backwards: off by %d and counted %v, want typed right and not counted", result.distance, result.Counts())
+	}
+}
+
+// Checks that the texts can be chosen in a given order and a round timed by a given clock,
+// which makes whole rounds predictable.
+func TestPlaySequenceAndClock(t *testing.T) {
+	useNewScores(t)
+	// The time is read when the round starts, when the line starts being typed and when the round ends.
+	useClock(t, 6*time.Second)
+	defer func(previous func(texts []textEntry) textEntry) { chooseText = previous }(chooseText)
+
+	texts := []string{"the quick brown fox", "hello world", "jumps over the lazy dog"}
+	chooseText = sequenceChooser([]int{1, 0, 4})
+	var chosen []string
+	for i := 0; i < 4; i++ {
+		chosen = append(chosen, chooseText(plainTexts(texts)).text)
+	}
+	if want := []string{texts[1], texts[0], texts[1], texts[1]}; strings.Join(chosen, "|") != strings.Join(want, "|") {
+		t.Fatalf("chose %q, want %q", chosen, want)
+	}
+
+	typeLine("helo world")
+	result := play(chosen[0])
+	if result.totalTime != 12*time.Second {
+		t.Errorf("the round took %v, want 12s", result.totalTime)
+	}
+	if result.distance != 1 || result.wpm != 10 || result.input != "helo world" {
+		t.Errorf("typed %q, off by %d at %v WPM, want \"helo world\", off by 1 at 10 WPM", result.input, result.distance, result.wpm)
+	}
+	if scores.Misses["l"] != 1 {
+		t.Errorf("the missed characters are %v, want the missed l", scores.Misses)
+	}
+}
+
+// Checks that a line entered in time is the input and that nothing is once the time runs out.
+func TestReadInputWithin(t *testing.T) {
+	typeLine("hello")
+	if input := readInputWithin(time.Minute); input != "hello\n" {
+		t.Errorf("readInputWithin with a line entered = %q, want %q", input, "hello\n")
+	}
+	if input := readInputWithin(time.Millisecond); input != "" {
+		t.Errorf("readInputWithin once the time ran out = %q, want nothing", input)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+// Merges two scores files, such as from different machines, into a new one:
+// typer merge a.json b.json -o out.json
+// Either file may be in any of the formats scores were ever saved in.
+func mergeScoresFiles(args []string) (err error) {
+	flags := flag.NewFlagSet("merge", flag.ContinueOnError)
+	out := flags.String("o", "", "the file to write the merged scores to")
+	history := flags.Int("max-history", 1000, "keep only this many of the most recent attempts at each text (0 means no limit)")
+
+	// The flags may come before, between or after the files.
+	var paths []string
+	for {
+		if err = flags.Parse(args); err != nil {
+			return
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		paths = append(paths, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	if len(paths) != 2 || *out == "" {
+		return fmt.Errorf("usage: typer merge a.json b.json -o out.json")
+	}
+
+	var loaded [2]Scores
+	for i, path := range paths {
+		if _, err = os.Stat(path); err != nil {
+			return
+		}
+		loaded[i] = newScores()
+		if err = loaded[i].loadFile(path); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	merged, conflicts := mergeScores(loaded[0], loaded[1])
+
+	*maxHistory = *history
+	scoresJson, err := merged.encode()
+	if err != nil {
+		return
+	}
+	if err = ioutil.WriteFile(*out, scoresJson, 0644); err != nil {
+		return fmt.Errorf("%w: %v", ErrScoresUnwritable, err)
+	}
+
+	fmt.Println("Merged", len(merged.Records), pluralize("text", len(merged.Records)), "into", *out)
+	fmt.Println(conflicts, pluralize("text", conflicts), "had records in both files, which were combined")
+
+	return
+}
+
+// Merges the scores, getting how many texts had a record in both.
+func mergeScores(a, b Scores) (merged Scores, conflicts int) {
+	merged = newScores()
+
+	for _, scores := range []Scores{a, b} {
+		for text, record := range scores.Records {
+			if existing, ok := merged.Records[text]; ok {
+				merged.Records[text] = mergeRecords(*existing, *record)
+				conflicts++
+			} else {
+				copied := *record
+				merged.Records[text] = &copied
+			}
+		}
+
+		for _, favorite := range scores.Favorites {
+			if !merged.IsFavorite(favorite) {
+				merged.Favorites = append(merged.Favorites, favorite)
+			}
+		}
+
+		for char, misses := range scores.Misses {
+			merged.Misses[char] += misses
+		}
+
+		for char, times := range scores.Keys {
+			merged.Keys[char] += times
+		}
+
+		merged.Sessions = append(merged.Sessions, scores.Sessions...)
+
+		for name, at := range scores.Achievements {
+			if merged.Achievements == nil {
+				merged.Achievements = make(map[string]time.Time)
+			}
+			if unlocked, ok := merged.Achievements[name]; !ok || at.Before(unlocked) {
+				merged.Achievements[name] = at
+			}
+		}
+
+		for milestone, at := range scores.Milestones {
+			if merged.Milestones == nil {
+				merged.Milestones = make(map[int]time.Time)
+			}
+			if reached, ok := merged.Milestones[milestone]; !ok || at.Before(reached) {
+				merged.Milestones[milestone] = at
+			}
+		}
+	}
+
+	for text, record := range merged.Records {
+		record.Difficulty = merged.rateDifficulty(text)
+	}
+
+	sort.SliceStable(merged.Sessions, func(i, j int) bool {
+		return merged.Sessions[i].Time.Before(merged.Sessions[j].Time)
+	})
+	merged.Sessions = dedupeSessions(merged.Sessions)
+	if len(merged.Sessions) > maxSessions {
+		merged.Sessions = merged.Sessions[len(merged.Sessions)-maxSessions:]
+	}
+
+	return
+}
+
+// Combines two records of the same text, keeping the better highscores and time and the attempts of both in order.
+// Attempts that are in both, such as when the files share a history, are only kept once.
+func mergeRecords(a, b Record) *Record {
+	merged := a
+	merged.Attempts = append(append([]Attempt(nil), a.Attempts...), b.Attempts...)
+	sort.SliceStable(merged.Attempts, func(i, j int) bool {
+		return merged.Attempts[i].Time.Before(merged.Attempts[j].Time)
+	})
+	merged.Attempts = dedupeAttempts(merged.Attempts)
+
+	if b.Highscore > merged.Highscore {
+		merged.Highscore = b.Highscore
+	}
+	merged.DistanceHighscores = nil
+	for _, record := range []Record{a, b} {
+		for algorithm, highscore := range record.DistanceHighscores {
+			if highscore > merged.highscore(algorithm) {
+				merged.setHighscore(algorithm, highscore)
+			}
+		}
+	}
+	if b.Fastest != 0 && (merged.Fastest == 0 || b.Fastest < merged.Fastest) {
+		merged.Fastest = b.Fastest
+	}
+	merged.Perfected = a.Perfected || b.Perfected
+	if b.Clean != 0 && (merged.Clean == 0 || b.Clean < merged.Clean) {
+		merged.Clean = b.Clean
+	}
+
+	return &merged
+}
+
+// Removes the attempts equal to the one before them from the sorted attempts.
+func dedupeAttempts(attempts []Attempt) []Attempt {
+	var kept []Attempt
+	for i, attempt := range attempts {
+		if i == 0 || !sameAttempt(attempt, attempts[i-1]) {
+			kept = append(kept, attempt)
+		}
+	}
+	return kept
+}
+
+// Reports whether the attempts are the same, even if their times are in different time zones.
+func sameAttempt(a, b Attempt) bool {
+	if !a.Time.Equal(b.Time) {
+		return false
+	}
+	a.Time, b.Time = time.Time{}, time.Time{}
+	return a == b
+}
+
+// Removes the sessions equal to the one before them from the sorted sessions.
+func dedupeSessions(sessions []SessionSummary) []SessionSummary {
+	var kept []SessionSummary
+	for i, session := range sessions {
+		if i == 0 || !session.Time.Equal(sessions[i-1].Time) || session.Rounds != sessions[i-1].Rounds {
+			kept = append(kept, session)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Checks that merging keeps every milestone reached in either file, each at the earlier time it was reached.
+func TestMergeScoresMilestones(t *testing.T) {
+	early := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	a, b := newScores(), newScores()
+	a.Milestones = map[int]time.Time{10: late, 20: early}
+	b.Milestones = map[int]time.Time{10: early, 30: late}
+
+	merged, _ := mergeScores(a, b)
+	want := map[int]time.Time{10: early, 20: early, 30: late}
+	if len(merged.Milestones) != len(want) {
+		t.Fatalf("merged milestones %v, want %v", merged.Milestones, want)
+	}
+	for milestone, at := range want {
+		if !merged.Milestones[milestone].Equal(at) {
+			t.Errorf("milestone %d reached at %v, want %v", milestone, merged.Milestones[milestone], at)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Gets the length of the text used for calculating the speed and accuracy.
+// With -printable-only, leading and trailing whitespace and characters that are not printable, such as tabs, are not counted.
+func textLength(text string) int {
+	if !*printableOnly {
+		return len([]rune(text))
+	}
+
+	length := 0
+	for _, r := range strings.TrimSpace(text) {
+		if unicode.IsPrint(r) {
+			length++
+		}
+	}
+	return length
+}
+
+// Calculates the typing speed in words per minute, counting five characters as a word.
+func getWPM(length int, totalTime time.Duration) float64 {
+	if totalTime <= 0 {
+		return 0
+	}
+	return float64(length) / 5 / totalTime.Minutes()
+}
+
+// Calculates the percentage of the text that was typed correctly.
+// An empty text is always typed with full accuracy, as there is nothing in it to get wrong.
+func getAccuracy(distance, length int) float64 {
+	if length == 0 {
+		return 100
+	}
+
+	accuracy := float64(length-distance) / float64(length) * 100
+	if accuracy < 0 {
+		return 0
+	}
+	return accuracy
+}
+
+// A word typed and how long it took.
+type wordTime struct {
+	word string
+	time time.Duration
+}
+
+// Gets how long each word of the input took to type, given when each of its characters was typed.
+// A word's time runs from when the whitespace before it was typed, or from the start for the first word,
+// to when its last character was typed.
+func timeWords(input []rune, typedAt []time.Duration) []wordTime {
+	var words []wordTime
+	var start time.Duration
+	wordStart := -1
+	for i, r := range input {
+		if unicode.IsSpace(r) {
+			if wordStart >= 0 {
+				words = append(words, wordTime{string(input[wordStart:i]), typedAt[i-1] - start})
+				wordStart = -1
+			}
+			start = typedAt[i]
+		} else if wordStart < 0 {
+			wordStart = i
+		}
+	}
+	if wordStart >= 0 {
+		words = append(words, wordTime{string(input[wordStart:]), typedAt[len(input)-1] - start})
+	}
+	return words
+}
+
+// How many of the slowest words are shown after a round.
+const slowestWords = 3
+
+// Prints the words that took the longest to type, starting with the slowest.
+func printSlowestWords(words []wordTime) {
+	if len(words) < 2 {
+		return
+	}
+
+	sorted := append([]wordTime(nil), words...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].time > sorted[j].time
+	})
+	if len(sorted) > slowestWords {
+		sorted = sorted[:slowestWords]
+	}
+
+	var listed []string
+	for _, word := range sorted {
+		listed = append(listed, fmt.Sprintf("%s (%.2fs)", word.word, word.time.Seconds()))
+	}
+	fmt.Println("Slowest words:", strings.Join(listed, ", "))
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Checks that the accuracy is a percentage for any distance and length, including an empty text.
+func TestGetAccuracy(t *testing.T) {
+	tests := []struct {
+		distance, length int
+		accuracy         float64
+	}{
+		{0, 4, 100},
+		{1, 4, 75},
+		{4, 4, 0},
+		{9, 4, 0},
+		{0, 0, 100},
+		{3, 0, 100},
+	}
+	for _, test := range tests {
+		if accuracy := getAccuracy(test.distance, test.length); accuracy != test.accuracy {
+			t.Errorf("getAccuracy(%d, %d) = %v, want %v", test.distance, test.length, accuracy, test.accuracy)
+		}
+	}
+}
+
+// Checks that five characters make a word and that no time makes for no speed rather than an infinite one.
+func TestGetWPM(t *testing.T) {
+	tests := []struct {
+		length    int
+		totalTime time.Duration
+		wpm       float64
+	}{
+		{50, time.Minute, 10},
+		{25, 30 * time.Second, 10},
+		{0, time.Minute, 0},
+		{50, 0, 0},
+		{50, -time.Second, 0},
+	}
+	for _, test := range tests {
+		if wpm := getWPM(test.length, test.totalTime); wpm != test.wpm {
+			t.Errorf("getWPM(%d, %v) = %v, want %v", test.length, test.totalTime, wpm, test.wpm)
+		}
+	}
+}
+
+// Checks that an empty text, an empty input or both are scored without breaking the scoring.
+func TestScoreInputEmpty(t *testing.T) {
+	tests := []struct {
+		input, text string
+		distance    int
+		accuracy    float64
+	}{
+		{"", "", 0, 100},
+		{"abc", "", 3, 100},
+		{"", "abc", 3, 0},
+		{"abc", "abc", 0, 100},
+		{"abd", "abcd", 1, 75},
+	}
+	for _, test := range tests {
+		for _, totalTime := range []time.Duration{0, time.Second} {
+			result := scoreInput(test.text, test.input, rawInput{}, totalTime)
+			if result.distance != test.distance || result.accuracy != test.accuracy {
+				t.Errorf("%q typed for %q in %v: off by %d with %v%% accuracy, want %d with %v%%",
+					test.input, test.text, totalTime, result.distance, result.accuracy, test.distance, test.accuracy)
+			}
+			if result.wpm < 0 || math.IsNaN(result.wpm) || math.IsInf(result.wpm, 0) {
+				t.Errorf("%q typed for %q in %v: speed %v WPM", test.input, test.text, totalTime, result.wpm)
+			}
+			if result.score < 0 || result.score > *baseScore {
+				t.Errorf("%q typed for %q in %v: score %d", test.input, test.text, totalTime, result.score)
+			}
+		}
+	}
+}
+
+// Checks that -printable-only leaves out tabs, trailing spaces and other characters that aren't printable.
+func TestTextLengthPrintableOnly(t *testing.T) {
+	defer func(printable bool) { *printableOnly = printable }(*printableOnly)
+
+	tests := []struct {
+		text              string
+		length, printable int
+	}{
+		{"hello", 5, 5},
+		{"a\tb", 3, 2},
+		{"hello world  ", 13, 11},
+		{"\tindented\t \n", 12, 8},
+		{"  \t ", 4, 0},
+	}
+	for _, test := range tests {
+		*printableOnly = false
+		if length := textLength(test.text); length != test.length {
+			t.Errorf("textLength(%q) = %d, want %d", test.text, length, test.length)
+		}
+		*printableOnly = true
+		if length := textLength(test.text); length != test.printable {
+			t.Errorf("textLength(%q) with -printable-only = %d, want %d", test.text, length, test.printable)
+		}
+	}
+
+	// The trailing spaces are only kept in the input with -exact-whitespace.
+	defer func(exact bool) { *exactWhitespace = exact }(*exactWhitespace)
+	*printableOnly, *exactWhitespace = true, true
+	result := scoreInput("\ta\tb  ", "\ta\tb  \n", rawInput{}, time.Minute)
+	if result.distance != 0 || result.accuracy != 100 || result.wpm != 0.4 {
+		t.Errorf("text with tabs and trailing spaces with -printable-only: off by %d with %v%% accuracy at %v WPM, want 0 with 100%% at 0.4 WPM",
+			result.distance, result.accuracy, result.wpm)
+	}
+}
+
+// Checks that only the characters typed correctly count toward the speed,
+// so that submitting little or nothing of a long text or typing garbage isn't fast.
+func TestScoreInputWPM(t *testing.T) {
+	text := strings.TrimSpace(strings.Repeat("typing ", 20))
+	tests := []struct {
+		input string
+		wpm   float64
+	}{
+		{"", 0},
+		{"typin", 1},
+		{strings.Repeat("x", len(text)), 0},
+		{strings.Repeat("x", 1000), 0},
+		{text, float64(len(text)) / 5},
+		{"t" + text[2:], float64(len(text)-1) / 5},
+	}
+	for _, test := range tests {
+		if result := scoreInput(text, test.input, rawInput{}, time.Minute); result.wpm != test.wpm {
+			t.Errorf("%q typed in a minute: %v WPM, want %v", test.input, result.wpm, test.wpm)
+		}
+	}
+}
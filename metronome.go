@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Rings the terminal bell.
+const bell = "\a"
+
+// Reports whether the metronome ticks.
+// It needs -metronome and a terminal to ring the bell on, and is silenced by -no-bell.
+func metronomeOn() bool {
+	return *metronome > 0 && !*noBell && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// The shortest time between beats of the metronome. Anything shorter could round down to no time at all.
+const minBeatInterval = time.Millisecond
+
+// Gets the time between beats of the metronome.
+func beatInterval() time.Duration {
+	return time.Minute / time.Duration(*metronome)
+}
+
+// Measures how far off the beat the keys were pressed on average, the first beat being at the start of the round.
+// The rhythm score goes from 100 if every key was pressed right on a beat to 0 if every key was pressed halfway between two beats.
+// Without -metronome, there is no beat and nothing is measured.
+func rhythmOf(keyTimes []time.Duration) (offBeat time.Duration, score float64) {
+	if *metronome <= 0 || len(keyTimes) == 0 {
+		return
+	}
+
+	interval := beatInterval()
+	var total time.Duration
+	for _, keyTime := range keyTimes {
+		off := keyTime % interval
+		if interval-off < off {
+			off = interval - off
+		}
+		total += off
+	}
+	offBeat = total / time.Duration(len(keyTimes))
+	score = 100 * (1 - float64(offBeat)/float64(interval/2))
+	return
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The least accuracy in percent a round needs for its speed to reach a milestone, so that mashing keys doesn't count.
+const milestoneAccuracy = 95
+
+// The fastest milestone of -milestone-step, as hardly anyone types faster.
+const maxMilestone = 300
+
+// Gets the speeds in WPM that are milestones, slowest first: the multiples of -milestone-step up to maxMilestone
+// and those given with -milestones.
+func milestones() []int {
+	seen := make(map[int]bool)
+	if *milestoneStep > 0 {
+		for milestone := *milestoneStep; milestone <= maxMilestone; milestone += *milestoneStep {
+			seen[milestone] = true
+		}
+	}
+	if *milestoneList != "" {
+		for _, field := range strings.Split(*milestoneList, ",") {
+			milestone, _ := strconv.Atoi(strings.TrimSpace(field))
+			seen[milestone] = true
+		}
+	}
+
+	var sorted []int
+	for milestone := range seen {
+		sorted = append(sorted, milestone)
+	}
+	sort.Ints(sorted)
+	return sorted
+}
+
+// Reports whether the milestones given with -milestones are all positive whole numbers.
+func validMilestoneList() bool {
+	if *milestoneList == "" {
+		return true
+	}
+	for _, field := range strings.Split(*milestoneList, ",") {
+		if milestone, err := strconv.Atoi(strings.TrimSpace(field)); err != nil || milestone <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Gets when an attempt of the records first reached the milestone, if any did.
+func firstReached(milestone int) (at time.Time, ok bool) {
+	for _, record := range scores.Records {
+		for _, attempt := range record.Attempts {
+			if attempt.WPM >= float64(milestone) && attempt.Accuracy >= milestoneAccuracy && (!ok || attempt.Time.Before(at)) {
+				at, ok = attempt.Time, true
+			}
+		}
+	}
+	return
+}
+
+// Celebrates the milestones the round reached for the first time and keeps them as reached so they aren't announced again.
+// If the round reached several at once, only the fastest is announced.
+// A milestone that an earlier attempt reached, such as one from before the milestones were kept, is kept as reached then
+// without being announced. This has to happen before the round is added to the records.
+func reachMilestones(result Result) {
+	if !result.Counts() || result.accuracy < milestoneAccuracy {
+		return
+	}
+
+	reached := 0
+	for _, milestone := range milestones() {
+		if float64(milestone) > result.wpm {
+			break
+		}
+		if _, ok := scores.Milestones[milestone]; ok {
+			continue
+		}
+
+		if scores.Milestones == nil {
+			scores.Milestones = make(map[int]time.Time)
+		}
+		if at, ok := firstReached(milestone); ok {
+			scores.Milestones[milestone] = at
+			continue
+		}
+		scores.Milestones[milestone] = now()
+		reached = milestone
+	}
+
+	if reached > 0 {
+		fmt.Printf("Milestone reached: you typed at %d WPM for the first time!\n", reached)
+	}
+}
+
+// Lists the milestones reached so far, if any.
+func printMilestones() {
+	var reached []int
+	for milestone := range scores.Milestones {
+		reached = append(reached, milestone)
+	}
+	if len(reached) == 0 {
+		return
+	}
+
+	sort.Ints(reached)
+	var listed []string
+	for _, milestone := range reached {
+		listed = append(listed, strconv.Itoa(milestone))
+	}
+	fmt.Println("Milestones reached:", strings.Join(listed, ", "), "WPM")
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The game modes that can be chosen with -mode.
+var modes = []struct{ name, description string }{
+	{"normal", "type random texts"},
+	{"review", "only type texts on your review list"},
+	{"focus", "practice the characters you miss most"},
+	{"drill", "type each text until you type it perfectly"},
+	{"code", "type code snippets, including their whitespace (raw mode)"},
+	{"word-reveal", "reveal the text one word at a time as you type it correctly (raw mode)"},
+	{"dictation", "type the words of random texts as they are dictated, spoken if a text-to-speech program is found (raw mode)"},
+	{"guided", "type random texts with a keyboard below them showing the key to press next, hidden and shown again with Ctrl+K (raw mode)"},
+	{"learn", "learn to type without time pressure: wrong keys aren't typed or counted and the key to press is hinted instead (raw mode)"},
+	{"consistency", "type each text a number of times in a row, seeing how steady your speed and accuracy are"},
+	{"comfort", "type texts about as long as those you recently typed well, with a longer one now and then"},
+	{"sprint", "type ever shorter texts in ever less time until one isn't typed in time"},
+	{"reverse", "type random texts backwards, without keeping highscores"},
+	{"numbers", "practice typing random digits"},
+	{"symbols", "practice typing random symbols used in programming"},
+	{"ngrams", "drill common letter sequences such as \"th\", \"ing\" and \"tion\""},
+}
+
+// Describes the game modes for the usage message.
+func describeModes() string {
+	var descriptions []string
+	for _, mode := range modes {
+		descriptions = append(descriptions, mode.name+" to "+mode.description)
+	}
+	return "the game mode: " + strings.Join(descriptions, ", ")
+}
+
+// Reports whether the game mode exists.
+func isValidMode(name string) bool {
+	for _, mode := range modes {
+		if mode.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Prepares the current mode before the first round.
+func setUpMode() {
+	switch *mode {
+	case "review":
+		printUnavailableFavorites()
+	case "dictation":
+		setUpDictation()
+	case "focus":
+		focusChars = scores.MostMissed(3)
+		if len(focusChars) == 0 {
+			fmt.Println("No mistakes recorded yet, so you will practice random words")
+		} else {
+			fmt.Println("Focusing on:", strings.Join(strings.Split(string(focusChars), ""), " "))
+		}
+	}
+}
+
+// Gets the texts to choose from in the current mode.
+func pool() []textEntry {
+	if *mode != "review" {
+		return text
+	}
+
+	var available []textEntry
+	for _, favorite := range scores.Favorites {
+		if entry, ok := findText(favorite); ok {
+			available = append(available, entry)
+		}
+	}
+	return available
+}
+
+// The characters practiced in focus mode.
+var focusChars []rune
+
+// Reports whether the texts of the current mode are generated rather than taken from the pool.
+// No highscores are kept for generated texts.
+func generatesTexts() bool {
+	return *mode == "focus" || *mode == "ngrams" || practicesChars()
+}
+
+// Reports whether records are kept for the text, which they aren't for generated texts or in learn mode.
+func keepsRecords(text string) bool {
+	return !generatesTexts() && *mode != "learn" && isKnownText(text)
+}
+
+// Reports whether the current mode practices single characters rather than words,
+// in which case the accuracy of each character is shown after a round.
+func practicesChars() bool {
+	return *mode == "numbers" || *mode == "symbols"
+}
+
+// Reports whether the current mode needs the input to be read key by key.
+func modeNeedsRawInput() bool {
+	return *mode == "word-reveal" || *mode == "code" || *mode == "learn" || *mode == "dictation"
+}
+
+// The state of drill mode.
+var drill struct {
+	// The text being drilled. This is empty if a new text is to be chosen.
+	text string
+	// The failed attempts at the text so far.
+	attempts int
+	// The best of the failed attempts.
+	best Result
+}
+
+// Gets the next text to be typed.
+// A text changed from one of the pool, such as a reversed one, keeps what is known about it.
+func nextText() textEntry {
+	switch *mode {
+	case "focus":
+		return textEntry{text: generateText(focusChars)}
+	case "numbers":
+		return textEntry{text: generateChars(digits)}
+	case "symbols":
+		return textEntry{text: generateChars(symbols)}
+	case "ngrams":
+		return textEntry{text: generateNgrams()}
+	}
+
+	if *mode == "drill" {
+		if drill.text == "" {
+			drill.text = chooseText(pool()).text
+		}
+		serve(drill.text)
+		return entryOf(drill.text)
+	}
+
+	if *mode == "consistency" {
+		if consistency.text == "" {
+			consistency.text = chooseText(pool()).text
+		}
+		serve(consistency.text)
+		return entryOf(consistency.text)
+	}
+
+	if *mode == "comfort" {
+		entry := comfortText()
+		serve(entry.text)
+		return entry
+	}
+
+	if *mode == "sprint" {
+		entry := entryOf(nextSprintText())
+		serve(entry.text)
+		return entry
+	}
+
+	if *mode == "reverse" {
+		entry := chooseText(pool())
+		serve(entry.text)
+		entry.text = reverse(entry.text)
+		return entry
+	}
+
+	if *mode == "normal" && len(sources) > 0 {
+		return nextSourceText()
+	}
+
+	entry := chooseText(pool())
+	serve(entry.text)
+	if *mode == "normal" {
+		entry.text = distract(entry.text)
+	}
+	return entry
+}
+
+// Gets the text of the pool the text being typed was served from, which differs from it in reverse mode and for distractors.
+// Commands after a round such as "review" act on that text, as the text being typed isn't one of the texts.
+func poolText(entry textEntry) textEntry {
+	if distraction.original != "" {
+		entry.text = distraction.original
+	} else if *mode == "reverse" {
+		entry.text = session.lastServed
+	}
+	return entry
+}
+
+// Lists the texts on the review list that can no longer be typed.
+func printUnavailableFavorites() {
+	for _, favorite := range scores.Favorites {
+		if !isKnownText(favorite) {
+			fmt.Printf("Unavailable: %q is no longer one of the texts\n", favorite)
+		}
+	}
+}
+
+// Gets the part of the text that is shown to the player, given the input so far.
+//
+// In word-reveal mode, only the words up to the one being typed are shown.
+// A word counts as typed once it and the space following it were typed correctly,
+// so punctuation attached to a word has to be typed too before the next word is revealed.
+// As long as a word is typed wrong, no more words are revealed until it is corrected.
+//
+// In dictation mode, only the words dictated so far are shown.
+func visibleText(input, text string) string {
+	if *mode == "dictation" {
+		return strings.Join(strings.Split(text, " ")[:dictation.dictated], " ")
+	}
+	if *mode != "word-reveal" {
+		return text
+	}
+
+	typed := strings.Split(input, " ")
+	textWords := strings.Split(text, " ")
+
+	revealed := 1
+	// The last field of the input is the word still being typed.
+	for i := 0; i < len(typed)-1 && i < len(textWords)-1; i++ {
+		if typed[i] != textWords[i] {
+			break
+		}
+		revealed++
+	}
+
+	return strings.Join(textWords[:revealed], " ")
+}
+
+// Decides after a round in drill mode whether the text has to be typed again.
+func continueDrill(result Result) {
+	if result.distance == 0 {
+		drill.text = ""
+		drill.attempts = 0
+		fmt.Println("Drilled! On to the next text.")
+		return
+	}
+
+	if drill.attempts == 0 || result.score > drill.best.score ||
+		(result.score == drill.best.score && result.distance < drill.best.distance) {
+		drill.best = result
+	}
+	drill.attempts++
+
+	if *maxAttempts > 0 && drill.attempts >= *maxAttempts {
+		fmt.Println("Moving on after", drill.attempts, pluralize("attempt", drill.attempts)+".",
+			"Your best attempt was off by", drill.best.distance, pluralize("character", drill.best.distance),
+			"with a score of", drill.best.score)
+		drill.text = ""
+		drill.attempts = 0
+		return
+	}
+
+	fmt.Println("Not quite! Type it again until it's perfect.")
+}
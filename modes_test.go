@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+)
+
+// Checks that the commands after a round act on the text of the pool rather than the reversed text or the distractor typed.
+func TestPoolText(t *testing.T) {
+	defer func(previous string) { *mode = previous }(*mode)
+	defer func(previous string) { session.lastServed = previous }(session.lastServed)
+
+	*mode = "normal"
+	if text := poolText(textEntry{text: "hello world"}).text; text != "hello world" {
+		t.Errorf("normal mode: %q, want the text typed", text)
+	}
+
+	*mode = "reverse"
+	session.lastServed = "hello world"
+	if text := poolText(textEntry{text: reverse("hello world")}).text; text != "hello world" {
+		t.Errorf("reverse mode: %q, want the text before it was reversed", text)
+	}
+
+	*mode = "normal"
+	distraction.original = "hello big world"
+	defer func() { distraction.original = "" }()
+	if text := poolText(textEntry{text: "hello world big"}).text; text != "hello big world" {
+		t.Errorf("distractor: %q, want the text it was made from", text)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// The most common letter sequences of English practiced in ngrams mode
+// and roughly how often they come up in English text, in percent of all sequences of their length.
+var ngramTable = []struct {
+	ngram     string
+	frequency float64
+}{
+	{"th", 3.56}, {"he", 3.07}, {"in", 2.43}, {"er", 2.05}, {"an", 1.99},
+	{"re", 1.85}, {"on", 1.76}, {"at", 1.49}, {"en", 1.45}, {"nd", 1.35},
+	{"ti", 1.34}, {"es", 1.34}, {"or", 1.28}, {"te", 1.20}, {"of", 1.17},
+	{"ed", 1.17}, {"is", 1.13}, {"it", 1.12}, {"al", 1.09}, {"ar", 1.07},
+	{"st", 1.05}, {"to", 1.05}, {"nt", 1.04}, {"ng", 0.95}, {"ou", 0.87},
+
+	{"the", 1.81}, {"and", 0.73}, {"ing", 0.72}, {"ent", 0.42}, {"ion", 0.42},
+	{"her", 0.36}, {"for", 0.34}, {"tha", 0.33}, {"nth", 0.33}, {"int", 0.32},
+	{"ere", 0.31}, {"tio", 0.31}, {"ter", 0.30}, {"est", 0.28}, {"ers", 0.28},
+	{"ati", 0.26}, {"hat", 0.26}, {"ate", 0.25}, {"all", 0.25}, {"eth", 0.24},
+
+	{"tion", 0.31}, {"that", 0.27}, {"ther", 0.24}, {"with", 0.18}, {"ment", 0.14},
+	{"ions", 0.14}, {"this", 0.14}, {"here", 0.13}, {"from", 0.13}, {"ould", 0.12},
+}
+
+// How many times in a row each n-gram is typed in a round of ngrams mode, to drill it.
+const ngramRepeats = 3
+
+// An n-gram practiced in ngrams mode and how often it is chosen.
+type ngramChoice struct {
+	ngram  string
+	weight float64
+}
+
+// Gets the n-grams to practice: those given with -ngrams, all chosen as often,
+// or those of the table with the length given with -ngram-length, chosen as often as they come up in English.
+func practicedNgrams() []ngramChoice {
+	var choices []ngramChoice
+	if *ngramSet != "" {
+		for _, ngram := range strings.Split(*ngramSet, ",") {
+			choices = append(choices, ngramChoice{strings.TrimSpace(ngram), 1})
+		}
+		return choices
+	}
+
+	for _, entry := range ngramTable {
+		if *ngramLength == 0 || utf8.RuneCountInString(entry.ngram) == *ngramLength {
+			choices = append(choices, ngramChoice{entry.ngram, entry.frequency})
+		}
+	}
+	return choices
+}
+
+// Reports whether the n-grams given with -ngrams are usable, none of them being empty or containing whitespace.
+func validNgramSet() bool {
+	for _, choice := range practicedNgrams() {
+		if choice.ngram == "" || strings.ContainsAny(choice.ngram, " \t\n") {
+			return false
+		}
+	}
+	return true
+}
+
+// Generates a text of at least -chars characters out of n-grams chosen at random by their weight,
+// each typed ngramRepeats times in a row and separated by spaces.
+func generateNgrams() string {
+	choices := practicedNgrams()
+	total := 0.0
+	for _, choice := range choices {
+		total += choice.weight
+	}
+
+	var ngrams []string
+	for length := 0; length < *charCount; {
+		pick := rng.Float64() * total
+		chosen := choices[len(choices)-1].ngram
+		for _, choice := range choices {
+			if pick < choice.weight {
+				chosen = choice.ngram
+				break
+			}
+			pick -= choice.weight
+		}
+
+		for i := 0; i < ngramRepeats; i++ {
+			ngrams = append(ngrams, chosen)
+			length += utf8.RuneCountInString(chosen) + 1
+		}
+	}
+	return strings.Join(ngrams, " ")
+}
+
+// Prints how many times each n-gram of the text was typed right and, in raw mode, how long it took on average,
+// starting with the n-gram typed right least often.
+func printNgramStats(ops []editOp, words []wordTime) {
+	total := make(map[string]int)
+	right := make(map[string]int)
+
+	// Each word of the text is one n-gram. The operations are split into the words of the text at its spaces,
+	// with any extra characters typed going into the word they were typed in.
+	var ngram strings.Builder
+	correct := true
+	count := func() {
+		if ngram.Len() == 0 {
+			return
+		}
+		total[ngram.String()]++
+		if correct {
+			right[ngram.String()]++
+		}
+		ngram.Reset()
+		correct = true
+	}
+	for _, op := range ops {
+		if op.kind != editInsertion && op.want == ' ' {
+			count()
+			continue
+		}
+		if op.kind != editInsertion {
+			ngram.WriteRune(op.want)
+		}
+		if op.kind != editMatch {
+			correct = false
+		}
+	}
+	count()
+
+	// The times of the n-grams typed right.
+	times := make(map[string][]time.Duration)
+	for _, word := range words {
+		if total[word.word] > 0 {
+			times[word.word] = append(times[word.word], word.time)
+		}
+	}
+
+	var ngrams []string
+	for ngram := range total {
+		ngrams = append(ngrams, ngram)
+	}
+	accuracy := func(ngram string) float64 {
+		return float64(right[ngram]) / float64(total[ngram]) * 100
+	}
+	// The average time is 0 for an n-gram that was never typed right or typed in line mode.
+	average := func(ngram string) time.Duration {
+		if len(times[ngram]) == 0 {
+			return 0
+		}
+		var sum time.Duration
+		for _, took := range times[ngram] {
+			sum += took
+		}
+		return sum / time.Duration(len(times[ngram]))
+	}
+	sort.Slice(ngrams, func(i, j int) bool {
+		a, b := accuracy(ngrams[i]), accuracy(ngrams[j])
+		if a != b {
+			return a < b
+		}
+		if average(ngrams[i]) != average(ngrams[j]) {
+			return average(ngrams[i]) > average(ngrams[j])
+		}
+		return ngrams[i] < ngrams[j]
+	})
+
+	var listed []string
+	for _, ngram := range ngrams {
+		stat := fmt.Sprintf("%s %.0f%%", ngram, accuracy(ngram))
+		if len(times[ngram]) > 0 {
+			stat += fmt.Sprintf(" (%.2fs)", average(ngram).Seconds())
+		}
+		listed = append(listed, stat)
+	}
+	fmt.Println("Accuracy per n-gram:", strings.Join(listed, ", "))
+}
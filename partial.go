@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Scores the round the player quit during in raw mode on the part of the text they got to,
+// so that the effort isn't wasted: it is shown and goes into the summary of the session, but not into the records of the text.
+func scorePartialRound(textToType string, input []rune, raw rawInput, elapsed time.Duration) {
+	if len(input) == 0 {
+		return
+	}
+
+	target := []rune(textToType)
+	if len(input) < len(target) {
+		target = target[:len(input)]
+	}
+
+	result := scoreInput(string(target), string(input), raw, elapsed)
+	fmt.Printf("Your unfinished round: %d of %d characters at %.1f WPM with %.1f%% accuracy\n",
+		len(target), len([]rune(textToType)), result.wpm, result.accuracy)
+
+	session.rounds++
+	if result.Counts() {
+		session.results = append(session.results, result)
+		session.score += result.score
+	}
+}
+
+// The round being typed in line mode, to tell the player what became of it if they quit during it.
+var lineRound struct {
+	sync.Mutex
+	// When the round started. This is the zero time if no round is being typed.
+	startedAt time.Time
+}
+
+// Notes that a round is being typed in line mode from now on, or no longer is.
+func trackLineRound(typing bool) {
+	lineRound.Lock()
+	defer lineRound.Unlock()
+
+	if typing {
+		lineRound.startedAt = now()
+	} else {
+		lineRound.startedAt = time.Time{}
+	}
+}
+
+// Tells the player who quit during a round in line mode that it can't be scored,
+// as the terminal discards the line being typed on Ctrl+C before it ever reaches the game.
+func reportLineRound() {
+	lineRound.Lock()
+	defer lineRound.Unlock()
+
+	if !lineRound.startedAt.IsZero() {
+		fmt.Printf("\nYour unfinished round of %v couldn't be scored, as the terminal discards what you typed on Ctrl+C. Use -raw to have it scored.\n",
+			now().Sub(lineRound.startedAt).Round(time.Second))
+	}
+}
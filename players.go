@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// A player taking turns with -players. Each player keeps their scores in the profile of their name.
+type player struct {
+	name string
+	// The scores of the player while it isn't their turn. During their turn, they are the global scores.
+	scores Scores
+	// The results of the player's turns this session.
+	results []Result
+	// How many rounds the player won this session.
+	wins int
+	// The results and score of the player's turns that count toward the stats and their streak of turns without any mistakes
+	// while it isn't their turn. During their turn, they are those of the session, so that no player gets another's.
+	counted       []Result
+	score         int
+	perfectStreak int
+}
+
+// The state of a session with several players, in which every player types the same text in turn each round.
+var multiplayer struct {
+	players []*player
+	// The player whose turn it is.
+	turn int
+	// The text of the round, served to every player.
+	text textEntry
+	// The results of the turns of the round so far, by player.
+	results []Result
+}
+
+// The modes whose rounds depend on how the previous rounds of the player went, so they can't be played in turns.
+var singlePlayerModes = map[string]bool{
+	"review": true, "drill": true, "consistency": true, "sprint": true, "learn": true,
+}
+
+// Reports whether several players take turns this session.
+func multiplaying() bool {
+	return len(multiplayer.players) > 0
+}
+
+// Gets the names of the players given with -players.
+func playerNames() []string {
+	var names []string
+	for _, name := range strings.Split(*players, ",") {
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names
+}
+
+// Exits if the players given with -players are invalid or can't play together in the current mode.
+func validatePlayers() {
+	if *players == "" {
+		return
+	}
+
+	names := playerNames()
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if !validProfileName.MatchString(name) {
+			fmt.Println("Invalid player name:", name)
+			fmt.Println("Use up to 64 letters, digits, - and _, as the scores of each player are kept in the profile of their name")
+			os.Exit(1)
+		}
+		if seen[name] {
+			fmt.Println("The player", name, "is given twice")
+			os.Exit(1)
+		}
+		seen[name] = true
+	}
+
+	if len(names) < 2 {
+		fmt.Println("It takes at least two players to take turns")
+		os.Exit(1)
+	}
+	if *profile != "" {
+		fmt.Println("-profile can't be used with -players, as each player has their own profile")
+		os.Exit(1)
+	}
+	if *untilScore > 0 {
+		fmt.Println("-until-score can't be used with -players")
+		os.Exit(1)
+	}
+	if singlePlayerModes[*mode] {
+		fmt.Println("Players can't take turns in", *mode, "mode")
+		os.Exit(1)
+	}
+}
+
+// Loads the scores of every player and makes it the first player's turn.
+func setUpPlayers() {
+	for _, name := range playerNames() {
+		*profile = name
+		scores = newScores()
+		if !*noSave {
+			loadScores()
+		}
+		multiplayer.players = append(multiplayer.players, &player{name: name, scores: scores})
+	}
+
+	multiplayer.turn = len(multiplayer.players) - 1
+	switchPlayer(0)
+}
+
+// Makes it the turn of the player with the given index, keeping the scores and the session of the player whose turn it was.
+func switchPlayer(turn int) {
+	current := multiplayer.players[multiplayer.turn]
+	current.scores = scores
+	current.counted, current.score, current.perfectStreak = session.results, session.score, session.perfectStreak
+
+	multiplayer.turn = turn
+	next := multiplayer.players[turn]
+	*profile = next.name
+	scores = next.scores
+	session.results, session.score, session.perfectStreak = next.counted, next.score, next.perfectStreak
+}
+
+// Gets the text of the round: a new one at the first player's turn, and the same one again at the turns of the others.
+func nextTurnText() textEntry {
+	if multiplayer.turn == 0 {
+		multiplayer.text = nextText()
+	}
+
+	fmt.Printf("%s, it's your turn! Press Enter when you're ready\n", multiplayer.players[multiplayer.turn].name)
+	readLine()
+	return multiplayer.text
+}
+
+// Gets how many turns a round has, which is one unless several players take turns.
+func turnsPerRound() int {
+	if multiplaying() {
+		return len(multiplayer.players)
+	}
+	return 1
+}
+
+// Ends the turn of the current player with their result and makes it the next player's turn.
+// Reports whether the round goes on with the next player. Otherwise, the round winner is announced.
+func finishTurn(result Result) (roundGoesOn bool) {
+	current := multiplayer.players[multiplayer.turn]
+	current.results = append(current.results, result)
+	multiplayer.results = append(multiplayer.results, result)
+
+	if multiplayer.turn < len(multiplayer.players)-1 {
+		switchPlayer(multiplayer.turn + 1)
+		fmt.Println()
+		return true
+	}
+
+	announceRoundWinner()
+	multiplayer.results = nil
+	switchPlayer(0)
+	return false
+}
+
+// Reports whether the first result beats the second: by score, then by speed.
+func beats(a, b Result) bool {
+	if a.score != b.score {
+		return a.score > b.score
+	}
+	return a.wpm > b.wpm
+}
+
+// Shows the results of every player in the round and who won it. A round with several best results has no winner.
+func announceRoundWinner() {
+	fmt.Println("\nThis round:")
+	best := 0
+	tied := false
+	for i, result := range multiplayer.results {
+		fmt.Printf("  %s: %d (%.1f WPM, %.1f%% accuracy)\n", multiplayer.players[i].name, result.score, result.wpm, result.accuracy)
+		if i == 0 {
+			continue
+		}
+		if beats(result, multiplayer.results[best]) {
+			best, tied = i, false
+		} else if !beats(multiplayer.results[best], result) {
+			tied = true
+		}
+	}
+
+	if tied {
+		fmt.Println("It's a tie!")
+		return
+	}
+	winner := multiplayer.players[best]
+	winner.wins++
+	fmt.Println(winner.name, "wins the round!")
+}
+
+// Shows how every player did this session, the one who won the most rounds first, and who won the session.
+func announceSessionWinner() {
+	if len(multiplayer.players[0].results) == 0 {
+		return
+	}
+
+	standings := append([]*player(nil), multiplayer.players...)
+	total := func(p *player) (score int) {
+		for _, result := range p.results {
+			score += result.score
+		}
+		return
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].wins != standings[j].wins {
+			return standings[i].wins > standings[j].wins
+		}
+		return total(standings[i]) > total(standings[j])
+	})
+
+	fmt.Println("This session:")
+	for _, p := range standings {
+		fmt.Printf("  %s: %d %s won, total score %d", p.name, p.wins, pluralize("round", p.wins), total(p))
+		if len(p.results) > 0 {
+			var wpm float64
+			for _, result := range p.results {
+				wpm += result.wpm
+			}
+			fmt.Printf(", %.1f WPM on average", wpm/float64(len(p.results)))
+		}
+		fmt.Println()
+	}
+
+	first, second := standings[0], standings[1]
+	if first.wins == second.wins && total(first) == total(second) {
+		fmt.Println("The session ends in a tie!")
+	} else {
+		fmt.Println(first.name, "wins the session!")
+	}
+}
+
+// Saves the scores, those of every player if several take turns.
+func saveScores() (err error) {
+	if !multiplaying() {
+		return scores.Save()
+	}
+
+	current := multiplayer.turn
+	for turn := range multiplayer.players {
+		switchPlayer(turn)
+		if err = scores.Save(); err != nil {
+			err = fmt.Errorf("%s: %w", multiplayer.players[turn].name, err)
+			break
+		}
+	}
+	switchPlayer(current)
+
+	return
+}
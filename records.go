@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// The least accuracy in percent an attempt has to be typed with for its time to count as the fastest,
+// so that it can't be set by just pressing Enter or typing garbage.
+const fastestAccuracy = 90
+
+// Everything kept about one text.
+type Record struct {
+	// The highscore made with the default -distance algorithm.
+	Highscore int `json:"highscore"`
+	// The highscores made with the other -distance algorithms, by algorithm.
+	// Scores measured with different algorithms count mistakes differently, so they are never compared.
+	DistanceHighscores map[string]int `json:"distance_highscores,omitempty"`
+	// The fewest seconds the text was typed in with at least fastestAccuracy.
+	// Like the highscore, this is kept even once the attempt is trimmed from the history.
+	Fastest float64 `json:"fastest,omitempty"`
+	// Whether the text was ever typed without any mistakes.
+	Perfected bool `json:"perfected,omitempty"`
+	// The fewest seconds the text was typed in without any mistakes, which is 0 if it never was.
+	// Unlike the fastest time, this can't come from a run with any mistakes.
+	Clean float64 `json:"clean,omitempty"`
+	// The attempts at typing the text, oldest first.
+	Attempts []Attempt `json:"attempts,omitempty"`
+	// How hard the text is for the player, based on the attempts. See rateDifficulty.
+	Difficulty string `json:"difficulty,omitempty"`
+}
+
+// One attempt at typing a text.
+type Attempt struct {
+	Time     time.Time `json:"time"`
+	Seconds  float64   `json:"seconds"`
+	Distance int       `json:"distance"`
+	Score    int       `json:"score"`
+	WPM      float64   `json:"wpm"`
+	Accuracy float64   `json:"accuracy"`
+	// The scoring variant the score was calculated with.
+	Scoring string `json:"scoring,omitempty"`
+	// The algorithm the distance was measured with. See distanceAlgorithms.
+	Algorithm string `json:"algorithm,omitempty"`
+	// Whether the score was made outside of a streak as long as -verified-highscore asked for, so it doesn't count toward the highscore.
+	Unverified bool `json:"unverified,omitempty"`
+	// Whether the text was served with two of its words swapped as a distractor with -distractors.
+	// Such attempts are scored against what was shown, so they don't count toward the highscore, fastest time or clean record.
+	Distractor bool `json:"distractor,omitempty"`
+}
+
+// Decodes the record, also accepting a bare highscore as it was saved before records existed.
+func (record *Record) UnmarshalJSON(data []byte) (err error) {
+	var highscore int
+	if json.Unmarshal(data, &highscore) == nil {
+		*record = Record{Highscore: highscore}
+		return
+	}
+
+	type plainRecord Record // avoids recursing into this method
+	return json.Unmarshal(data, (*plainRecord)(record))
+}
+
+// Gets the highscore made with the -distance algorithm, where no algorithm is the default one.
+func (record Record) highscore(algorithm string) int {
+	if algorithm == "" || algorithm == defaultDistance {
+		return record.Highscore
+	}
+	return record.DistanceHighscores[algorithm]
+}
+
+// Sets the highscore made with the -distance algorithm, where no algorithm is the default one.
+func (record *Record) setHighscore(algorithm string, score int) {
+	if algorithm == "" || algorithm == defaultDistance {
+		record.Highscore = score
+		return
+	}
+	if record.DistanceHighscores == nil {
+		record.DistanceHighscores = make(map[string]int)
+	}
+	record.DistanceHighscores[algorithm] = score
+}
+
+// Gets the average distance and the average seconds taken per character across the attempts.
+// An empty text is taken to be one character long, so that its pace is finite.
+func (record Record) averages(text string) (distance, pace float64) {
+	length := float64(len([]rune(text)))
+	if length == 0 {
+		length = 1
+	}
+	for _, attempt := range record.Attempts {
+		distance += float64(attempt.Distance)
+		pace += attempt.Seconds / length
+	}
+
+	n := float64(len(record.Attempts))
+	return distance / n, pace / n
+}
+
+// Adds an attempt at the text and updates how hard the text is for the player.
+func (scores Scores) AddAttempt(text string, result Result, at time.Time) {
+	scores.addAttempt(text, newAttempt(result, at))
+}
+
+// Gets the attempt the result of a round is saved as.
+func newAttempt(result Result, at time.Time) Attempt {
+	return Attempt{
+		Time:       at,
+		Seconds:    result.totalTime.Seconds(),
+		Distance:   result.distance,
+		Score:      result.score,
+		WPM:        result.wpm,
+		Accuracy:   result.accuracy,
+		Scoring:    result.scoring,
+		Algorithm:  result.algorithm,
+		Unverified: unverified(result),
+	}
+}
+
+// Gets the streak of rounds in a row without any mistakes the round ends, counting the round itself,
+// or 0 if the round had mistakes because it breaks the streak. This must be called before the streak of the session is updated.
+func streakWith(result Result) int {
+	if isPerfect(result) {
+		return session.perfectStreak + 1
+	}
+	return 0
+}
+
+// Reports whether the score of the round doesn't count as a highscore because it wasn't made during a streak as long as -verified-highscore asks for.
+func unverified(result Result) bool {
+	return streakWith(result) < *verifiedStreak
+}
+
+// Adds the attempt to the record of the text, which must exist, and updates how hard the text is for the player.
+func (scores Scores) addAttempt(text string, attempt Attempt) {
+	record := scores.Records[text]
+	record.Attempts = append(record.Attempts, attempt)
+	record.keepBest(attempt)
+	record.Difficulty = scores.rateDifficulty(text)
+}
+
+// Updates the highscore of the attempt's -distance algorithm, the fastest time and the clean record if the attempt beat them,
+// and notes a perfect attempt. Attempts at distractors are left out.
+func (record *Record) keepBest(attempt Attempt) {
+	if attempt.Distractor {
+		return
+	}
+	if attempt.Score > record.highscore(attempt.Algorithm) && !attempt.Unverified {
+		record.setHighscore(attempt.Algorithm, attempt.Score)
+	}
+	if attempt.Accuracy >= fastestAccuracy && (record.Fastest == 0 || attempt.Seconds < record.Fastest) {
+		record.Fastest = attempt.Seconds
+	}
+	if attempt.Distance == 0 {
+		record.Perfected = true
+		if record.Clean == 0 || attempt.Seconds < record.Clean {
+			record.Clean = attempt.Seconds
+		}
+	}
+}
+
+// Gets the fewest seconds the text was typed in without any mistakes, which is 0 if it never was.
+func (record Record) cleanRecord() float64 {
+	clean := record.Clean
+
+	// Attempts saved before clean records were kept track of.
+	for _, attempt := range record.Attempts {
+		if attempt.Distance == 0 && !attempt.Distractor && (clean == 0 || attempt.Seconds < clean) {
+			clean = attempt.Seconds
+		}
+	}
+	return clean
+}
+
+// Reports whether the text was ever typed without any mistakes.
+func (record Record) perfected() bool {
+	if record.Perfected {
+		return true
+	}
+
+	// Attempts saved before perfect runs were kept track of.
+	for _, attempt := range record.Attempts {
+		if attempt.Distance == 0 && !attempt.Distractor {
+			return true
+		}
+	}
+	return false
+}
+
+// Counts the attempts at distractors made from the text and how many of them were typed perfectly.
+func (record Record) distractors() (attempts, perfect int) {
+	for _, attempt := range record.Attempts {
+		if attempt.Distractor {
+			attempts++
+			if attempt.Distance == 0 {
+				perfect++
+			}
+		}
+	}
+	return
+}
+
+// Gets when the text was last played, which is the zero time if there are no attempts at it.
+func (record Record) lastPlayed() time.Time {
+	if len(record.Attempts) == 0 {
+		return time.Time{}
+	}
+	return record.Attempts[len(record.Attempts)-1].Time
+}
+
+// Trims the history to keep the scores file bounded:
+// each text keeps only its -max-history most recent attempts,
+// and only the -max-texts texts played most recently are kept.
+// The highscore, fastest time and clean record of a text are kept even when the attempts they came from are trimmed.
+func (scores Scores) trimHistory() {
+	if *maxHistory > 0 {
+		for _, record := range scores.Records {
+			if len(record.Attempts) <= *maxHistory {
+				continue
+			}
+
+			trimmed := len(record.Attempts) - *maxHistory
+			for _, attempt := range record.Attempts[:trimmed] {
+				record.keepBest(attempt)
+			}
+			record.Attempts = append([]Attempt(nil), record.Attempts[trimmed:]...)
+		}
+	}
+
+	if *maxTexts > 0 && len(scores.Records) > *maxTexts {
+		var texts []string
+		for text := range scores.Records {
+			texts = append(texts, text)
+		}
+		sort.Slice(texts, func(i, j int) bool {
+			return scores.Records[texts[i]].lastPlayed().After(scores.Records[texts[j]].lastPlayed())
+		})
+
+		for _, text := range texts[*maxTexts:] {
+			delete(scores.Records, text)
+		}
+	}
+}
+
+// Rates how hard the text is for the player as "easy", "medium" or "hard",
+// comparing the average distance and typing pace on the text against the player's pace across all texts.
+func (scores Scores) rateDifficulty(text string) string {
+	record := scores.Records[text]
+	if len(record.Attempts) == 0 {
+		return ""
+	}
+
+	distance, pace := record.averages(text)
+
+	var totalPace float64
+	texts := 0
+	for otherText, other := range scores.Records {
+		if len(other.Attempts) > 0 {
+			_, otherPace := other.averages(otherText)
+			totalPace += otherPace
+			texts++
+		}
+	}
+	overallPace := totalPace / float64(texts)
+
+	switch {
+	case distance >= 3 || pace > overallPace*1.25:
+		return "hard"
+	case distance < 1 && pace < overallPace*0.9:
+		return "easy"
+	default:
+		return "medium"
+	}
+}
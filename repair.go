@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"unicode/utf8"
+)
+
+// Checks the scores file for inconsistencies, such as attempts with times that can't be right,
+// and writes a repaired version, keeping the original next to it as a backup.
+// The repairs are conservative: only what can't be right is removed or reset, and the highscore,
+// fastest time and clean record of a text are never lowered or dropped unless they are invalid themselves.
+// A file that can't be read as scores at all can't be repaired.
+func repairScores(args []string) (err error) {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: typer [-profile name] repair")
+	}
+
+	path := scoresPath()
+	original, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		fmt.Println("There is no scores file to repair at", path)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%w: %v", ErrScoresUnreadable, err)
+	}
+
+	scores = newScores()
+	if err = scores.loadFile(path); err != nil {
+		return fmt.Errorf("%v\nIt can't be repaired, but you can move it out of the way to start afresh", err)
+	}
+
+	var fixes []string
+	fixed := func(format string, args ...interface{}) {
+		fixes = append(fixes, fmt.Sprintf(format, args...))
+	}
+
+	if isOldFormat(original) {
+		fixed("converted the scores from an older format")
+	}
+	repairRecords(fixed)
+	repairHeatmap("misses", scores.Misses, fixed)
+	repairHeatmap("keys", scores.Keys, fixed)
+	repairFavorites(fixed)
+	repairSessions(fixed)
+	repairAchievements(fixed)
+	repairMilestones(fixed)
+
+	if len(fixes) == 0 {
+		fmt.Println("Nothing to repair in", path)
+		return nil
+	}
+
+	backup := path + ".bak"
+	if err = ioutil.WriteFile(backup, original, 0644); err != nil {
+		return fmt.Errorf("failed to back up the scores file: %v", err)
+	}
+	if err = scores.Save(); err != nil {
+		return
+	}
+
+	fmt.Println("Repaired", path+":")
+	for _, fix := range fixes {
+		fmt.Println("  " + fix)
+	}
+	fmt.Println("The original was kept as", backup)
+
+	return
+}
+
+// Reports whether the scores file is in one of the formats used before the scores were kept under "records".
+func isOldFormat(content []byte) bool {
+	if bytes.HasPrefix(content, gzipMagic) {
+		decompressed, err := decompress(content)
+		if err != nil {
+			return false
+		}
+		content = decompressed
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(content, &fields) != nil {
+		return false
+	}
+	_, hasRecords := fields["records"]
+	return !hasRecords
+}
+
+// Reports whether the number is one that can't come from playing, being negative, infinite or not a number.
+func invalidNumber(n float64) bool {
+	return n < 0 || math.IsNaN(n) || math.IsInf(n, 0)
+}
+
+// Reports whether the attempt can't have been played: it has no time, took no time or has values out of range.
+func invalidAttempt(attempt Attempt) bool {
+	return attempt.Time.IsZero() || attempt.Seconds <= 0 || invalidNumber(attempt.Seconds) ||
+		attempt.Distance < 0 || attempt.Score < 0 || invalidNumber(attempt.WPM) ||
+		invalidNumber(attempt.Accuracy) || attempt.Accuracy > 100
+}
+
+// Removes empty records and invalid attempts, resets invalid bests and puts the attempts in order.
+func repairRecords(fixed func(format string, args ...interface{})) {
+	var texts []string
+	for text := range scores.Records {
+		texts = append(texts, text)
+	}
+	sort.Strings(texts)
+
+	for _, text := range texts {
+		record := scores.Records[text]
+		if text == "" || record == nil {
+			delete(scores.Records, text)
+			fixed("removed an empty record")
+			continue
+		}
+
+		if record.Highscore < 0 {
+			record.Highscore = 0
+			fixed("reset the negative highscore of %q", text)
+		}
+		for algorithm, highscore := range record.DistanceHighscores {
+			if highscore < 0 {
+				record.DistanceHighscores[algorithm] = 0
+				fixed("reset the negative %s highscore of %q", algorithm, text)
+			}
+		}
+		if record.Fastest < 0 || invalidNumber(record.Fastest) {
+			record.Fastest = 0
+			fixed("reset the invalid fastest time of %q", text)
+		}
+		if record.Clean < 0 || invalidNumber(record.Clean) {
+			record.Clean = 0
+			fixed("reset the invalid clean record of %q", text)
+		}
+
+		var valid []Attempt
+		for _, attempt := range record.Attempts {
+			if !invalidAttempt(attempt) {
+				valid = append(valid, attempt)
+			}
+		}
+		if removed := len(record.Attempts) - len(valid); removed > 0 {
+			fixed("removed %d invalid %s of %q", removed, pluralize("attempt", removed), text)
+		}
+		record.Attempts = valid
+
+		if !sort.SliceIsSorted(record.Attempts, func(i, j int) bool {
+			return record.Attempts[i].Time.Before(record.Attempts[j].Time)
+		}) {
+			sort.SliceStable(record.Attempts, func(i, j int) bool {
+				return record.Attempts[i].Time.Before(record.Attempts[j].Time)
+			})
+			fixed("put the attempts at %q in order", text)
+		}
+
+		// The bests are only ever raised from the attempts left, never lowered.
+		for _, attempt := range record.Attempts {
+			record.keepBest(attempt)
+		}
+	}
+
+	for _, text := range texts {
+		record, ok := scores.Records[text]
+		if !ok {
+			continue
+		}
+		if difficulty := scores.rateDifficulty(text); record.Difficulty != difficulty {
+			record.Difficulty = difficulty
+			fixed("rated the difficulty of %q anew", text)
+		}
+	}
+}
+
+// Removes the entries of the heatmap with the given name that aren't single characters or have no count.
+func repairHeatmap(name string, heatmap map[string]int, fixed func(format string, args ...interface{})) {
+	removed := 0
+	for char, count := range heatmap {
+		if utf8.RuneCountInString(char) != 1 || count <= 0 {
+			delete(heatmap, char)
+			removed++
+		}
+	}
+	if removed > 0 {
+		fixed("removed %d %s that weren't single characters with a count", removed, name)
+	}
+}
+
+// Removes empty texts and texts listed twice from the review list.
+// Texts without a record are kept, as they may just not have been typed yet.
+func repairFavorites(fixed func(format string, args ...interface{})) {
+	seen := make(map[string]bool)
+	var favorites []string
+	for _, favorite := range scores.Favorites {
+		if favorite != "" && !seen[favorite] {
+			favorites = append(favorites, favorite)
+		}
+		seen[favorite] = true
+	}
+	if removed := len(scores.Favorites) - len(favorites); removed > 0 {
+		fixed("removed %d empty or repeated %s from the review list", removed, pluralize("text", removed))
+		scores.Favorites = favorites
+	}
+}
+
+// Removes the session summaries without any rounds or with values out of range.
+func repairSessions(fixed func(format string, args ...interface{})) {
+	var sessions []SessionSummary
+	for _, summary := range scores.Sessions {
+		if summary.Rounds > 0 && !invalidNumber(summary.WPM) && !invalidNumber(summary.Accuracy) && summary.Accuracy <= 100 {
+			sessions = append(sessions, summary)
+		}
+	}
+	if removed := len(scores.Sessions) - len(sessions); removed > 0 {
+		fixed("removed the invalid summaries of %d %s", removed, pluralize("session", removed))
+		scores.Sessions = sessions
+	}
+}
+
+// Removes the achievements that don't exist.
+func repairAchievements(fixed func(format string, args ...interface{})) {
+	known := make(map[string]bool)
+	for _, achievement := range achievements {
+		known[achievement.id] = true
+	}
+	for id := range scores.Achievements {
+		if !known[id] {
+			delete(scores.Achievements, id)
+			fixed("removed the unknown achievement %q", id)
+		}
+	}
+}
+
+// Removes the milestones that aren't positive speeds or weren't reached at any time.
+func repairMilestones(fixed func(format string, args ...interface{})) {
+	removed := 0
+	for milestone, at := range scores.Milestones {
+		if milestone <= 0 || at.IsZero() {
+			delete(scores.Milestones, milestone)
+			removed++
+		}
+	}
+	if removed > 0 {
+		fixed("removed %d invalid %s", removed, pluralize("milestone", removed))
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// The scripts written from right to left.
+var rightToLeftScripts = []*unicode.RangeTable{
+	unicode.Arabic, unicode.Hebrew, unicode.Syriac, unicode.Thaana, unicode.Nko, unicode.Samaritan, unicode.Mandaic,
+}
+
+// Reports whether the text is written from right to left, which it is if it has more letters in right-to-left scripts than others.
+//
+// Terminals lay out right-to-left text in different ways, if at all, so the input can't be typed over such a text
+// without garbling it. Instead, the text is shown on a line of its own and the input is typed on the line below it.
+// Only the display is affected: the input is compared with the text character by character in the order they were typed,
+// so the scoring is the same as for any other text.
+func isRightToLeft(text string) bool {
+	rightToLeft, leftToRight := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.In(r, rightToLeftScripts...):
+			rightToLeft++
+		case unicode.IsLetter(r):
+			leftToRight++
+		}
+	}
+	return rightToLeft > leftToRight
+}
+
+// Draws a right-to-left text and the input typed below it in raw mode.
+type rawLines struct {
+	// Whether the lines were drawn before, in which case the cursor is on the line of the input.
+	drawn bool
+}
+
+// Redraws the text and the input on the line below it, with the cursor placed right after the input.
+// If the status is not empty, it is shown below the input.
+func (lines *rawLines) render(input []rune, textToType string, status string) {
+	var out strings.Builder
+	if lines.drawn {
+		out.WriteString("\x1b[1A") // move the cursor up to the line of the text
+	}
+	out.WriteString("\r")
+	out.WriteString(prefix)
+	out.WriteString(textToType)
+	out.WriteString("\x1b[K\r\n") // clear the rest of the line
+	out.WriteString(prefix)
+	out.WriteString(paintInput(input, []rune(textToType)))
+	out.WriteString("\x1b[K")
+	if status != "" {
+		out.WriteString("\r\n")
+		out.WriteString(status)
+		out.WriteString("\x1b[K\x1b[1A") // clear the rest of the line and move back up
+	}
+	out.WriteString("\r")
+	if column := len(prefix) + len(input); column > 0 {
+		fmt.Fprintf(&out, "\x1b[%dC", column) // move the cursor to the end of the input
+	}
+
+	lines.drawn = true
+	fmt.Print(out.String())
+}
+
+// Removes the status line below the input once the input is done.
+func (lines *rawLines) finish(input []rune, status string) {
+	if status != "" {
+		clearStatusLine()
+	}
+}
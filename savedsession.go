@@ -0,0 +1,366 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// The version of the format of the files written with -save-session.
+// It goes up whenever the format changes so that older versions of typer can't resume the sessions saved by newer ones.
+const sessionFormat = 1
+
+// A session saved with -save-session to be resumed with -load-session.
+type savedSession struct {
+	Version int    `json:"version"`
+	Mode    string `json:"mode"`
+	// The limits the session was started with, which it goes on with unless other ones are given when resuming it.
+	Count      int `json:"count,omitempty"`
+	UntilScore int `json:"until_score,omitempty"`
+	// The state of the random numbers choosing the texts: the seed and how many numbers were generated since.
+	Seed           int64  `json:"seed"`
+	Draws          uint64 `json:"draws"`
+	RecentRandInts []int  `json:"recent_rand_ints,omitempty"`
+
+	Rounds        int            `json:"rounds"`
+	Results       []savedResult  `json:"results,omitempty"`
+	Served        map[string]int `json:"served,omitempty"`
+	LastServed    string         `json:"last_served,omitempty"`
+	PerfectStreak int            `json:"perfect_streak,omitempty"`
+	Score         int            `json:"score,omitempty"`
+	Mistakes      []savedReview  `json:"mistakes,omitempty"`
+
+	Drill       *savedDrill       `json:"drill,omitempty"`
+	Consistency *savedConsistency `json:"consistency,omitempty"`
+	Sprint      *savedSprint      `json:"sprint,omitempty"`
+
+	// When the summary of the session was added to the scores on quitting, as it is taken back when the session is resumed.
+	// This is the zero time if no summary was added.
+	Summarized time.Time `json:"summarized"`
+}
+
+// A Result as saved with the session.
+type savedResult struct {
+	Time         time.Duration `json:"time"`
+	Distance     int           `json:"distance"`
+	Score        int           `json:"score"`
+	Corrections  int           `json:"corrections,omitempty"`
+	WrongKeys    int           `json:"wrong_keys,omitempty"`
+	WPM          float64       `json:"wpm"`
+	Accuracy     float64       `json:"accuracy"`
+	Ops          []savedOp     `json:"ops,omitempty"`
+	Scoring      string        `json:"scoring"`
+	Input        string        `json:"input"`
+	OffBeat      time.Duration `json:"off_beat,omitempty"`
+	Rhythm       float64       `json:"rhythm,omitempty"`
+	Chatter      int           `json:"chatter,omitempty"`
+	Words        []savedWord   `json:"words,omitempty"`
+	Truncated    bool          `json:"truncated,omitempty"`
+	FalseStarts  int           `json:"false_starts,omitempty"`
+	ClockSkewed  bool          `json:"clock_skewed,omitempty"`
+	Keystrokes   int           `json:"keystrokes,omitempty"`
+	DictationLag time.Duration `json:"dictation_lag,omitempty"`
+	Algorithm    string        `json:"algorithm"`
+}
+
+// An editOp as saved with the session.
+type savedOp struct {
+	Kind  editKind `json:"kind"`
+	Typed rune     `json:"typed,omitempty"`
+	Want  rune     `json:"want,omitempty"`
+}
+
+// A wordTime as saved with the session.
+type savedWord struct {
+	Word string        `json:"word"`
+	Time time.Duration `json:"time"`
+}
+
+// A round kept for -review as saved with the session.
+type savedReview struct {
+	Text   string      `json:"text"`
+	Result savedResult `json:"result"`
+}
+
+// The state of drill mode as saved with the session.
+type savedDrill struct {
+	Text     string      `json:"text"`
+	Attempts int         `json:"attempts"`
+	Best     savedResult `json:"best"`
+}
+
+// The state of consistency mode as saved with the session.
+type savedConsistency struct {
+	Text string        `json:"text"`
+	Runs []savedResult `json:"runs,omitempty"`
+}
+
+// The state of sprint mode as saved with the session.
+type savedSprint struct {
+	Texts  []string      `json:"texts"`
+	Stage  int           `json:"stage"`
+	Budget time.Duration `json:"budget"`
+	Over   bool          `json:"over,omitempty"`
+}
+
+// Converts the result into the form it is saved in.
+func saveResult(result Result) savedResult {
+	saved := savedResult{
+		Time:         result.totalTime,
+		Distance:     result.distance,
+		Score:        result.score,
+		Corrections:  result.corrections,
+		WrongKeys:    result.wrongKeys,
+		WPM:          result.wpm,
+		Accuracy:     result.accuracy,
+		Scoring:      result.scoring,
+		Input:        result.input,
+		OffBeat:      result.offBeat,
+		Rhythm:       result.rhythm,
+		Chatter:      result.chatter,
+		Truncated:    result.truncated,
+		FalseStarts:  result.falseStarts,
+		ClockSkewed:  result.clockSkewed,
+		Keystrokes:   result.keystrokes,
+		DictationLag: result.dictationLag,
+		Algorithm:    result.algorithm,
+	}
+	for _, op := range result.ops {
+		saved.Ops = append(saved.Ops, savedOp{op.kind, op.typed, op.want})
+	}
+	for _, word := range result.words {
+		saved.Words = append(saved.Words, savedWord{word.word, word.time})
+	}
+	return saved
+}
+
+// Converts the saved result back into a Result.
+func (saved savedResult) result() Result {
+	result := Result{
+		totalTime:    saved.Time,
+		distance:     saved.Distance,
+		score:        saved.Score,
+		corrections:  saved.Corrections,
+		wrongKeys:    saved.WrongKeys,
+		wpm:          saved.WPM,
+		accuracy:     saved.Accuracy,
+		scoring:      saved.Scoring,
+		input:        saved.Input,
+		offBeat:      saved.OffBeat,
+		rhythm:       saved.Rhythm,
+		chatter:      saved.Chatter,
+		truncated:    saved.Truncated,
+		falseStarts:  saved.FalseStarts,
+		clockSkewed:  saved.ClockSkewed,
+		keystrokes:   saved.Keystrokes,
+		dictationLag: saved.DictationLag,
+		algorithm:    saved.Algorithm,
+	}
+	for _, op := range saved.Ops {
+		result.ops = append(result.ops, editOp{op.Kind, op.Typed, op.Want})
+	}
+	for _, word := range saved.Words {
+		result.words = append(result.words, wordTime{word.Word, word.Time})
+	}
+	return result
+}
+
+// Converts the results into the form they are saved in.
+func saveResults(results []Result) []savedResult {
+	var saved []savedResult
+	for _, result := range results {
+		saved = append(saved, saveResult(result))
+	}
+	return saved
+}
+
+// Converts the saved results back into Results.
+func restoreResults(saved []savedResult) []Result {
+	var results []Result
+	for _, result := range saved {
+		results = append(results, result.result())
+	}
+	return results
+}
+
+// A source of random numbers that counts how many numbers it generated,
+// so that its state can be saved as its seed and the count and restored by generating as many numbers again.
+type countingSource struct {
+	source rand.Source
+	seed   int64
+	draws  uint64
+}
+
+// Makes a counting source with the seed.
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{rand.NewSource(seed), seed, 0}
+}
+
+// Generates a random number, counting it.
+func (source *countingSource) Int63() int64 {
+	source.draws++
+	return source.source.Int63()
+}
+
+// Seeds the source anew, which starts the count over.
+func (source *countingSource) Seed(seed int64) {
+	source.source.Seed(seed)
+	source.seed = seed
+	source.draws = 0
+}
+
+// The session to resume with -load-session. This is nil if no session is resumed.
+var resumed *savedSession
+
+// Reads the session to resume with -load-session, going on with its mode and limits.
+// If there is no session saved at the path yet, a new one is started, so that the same file can be given with
+// -save-session and -load-session every time. A file that isn't a saved session, or one saved by a newer version of typer, exits.
+func loadSavedSession() {
+	if *loadSessionFrom == "" {
+		return
+	}
+
+	saved, err := readSession(*loadSessionFrom)
+	if os.IsNotExist(err) {
+		fmt.Println("There is no saved session at", *loadSessionFrom+", so a new one is started")
+		return
+	} else if err != nil {
+		fmt.Println("Failed to resume the session:", err)
+		os.Exit(1)
+	}
+
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	if given["mode"] && *mode != saved.Mode {
+		fmt.Printf("The saved session is in %s mode, so it can't be resumed in %s mode\n", saved.Mode, *mode)
+		os.Exit(1)
+	}
+	*mode = saved.Mode
+	if !given["count"] {
+		*count = saved.Count
+	}
+	if !given["until-score"] {
+		*untilScore = saved.UntilScore
+	}
+
+	resumed = saved
+}
+
+// Reads the session saved at the path.
+func readSession(path string) (saved *savedSession, err error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	saved = &savedSession{}
+	if err = json.Unmarshal(content, saved); err != nil || saved.Version == 0 {
+		return nil, fmt.Errorf("%s isn't a saved session", path)
+	}
+	if saved.Version > sessionFormat {
+		return nil, fmt.Errorf("%s was saved by a newer version of typer (format %d, this version reads up to %d)", path, saved.Version, sessionFormat)
+	}
+	return
+}
+
+// Picks the session read with -load-session up where it was left off.
+// The summary added to the scores when it was left is taken back, as a new one is added when it ends again.
+func resumeSession() {
+	if resumed == nil {
+		return
+	}
+
+	randIntSrc.Seed(resumed.Seed)
+	for randIntSrc.draws < resumed.Draws {
+		randIntSrc.Int63()
+	}
+	recentRandInts = resumed.RecentRandInts
+
+	session.rounds = resumed.Rounds
+	session.results = restoreResults(resumed.Results)
+	session.served = resumed.Served
+	session.lastServed = resumed.LastServed
+	session.perfectStreak = resumed.PerfectStreak
+	session.score = resumed.Score
+	for _, round := range resumed.Mistakes {
+		session.mistakes = append(session.mistakes, reviewedRound{round.Text, round.Result.result()})
+	}
+
+	if saved := resumed.Drill; saved != nil {
+		drill.text, drill.attempts, drill.best = saved.Text, saved.Attempts, saved.Best.result()
+	}
+	if saved := resumed.Consistency; saved != nil {
+		consistency.text, consistency.runs = saved.Text, restoreResults(saved.Runs)
+	}
+	if saved := resumed.Sprint; saved != nil {
+		sprint.texts, sprint.stage, sprint.budget, sprint.over = saved.Texts, saved.Stage, saved.Budget, saved.Over
+	}
+
+	if *count > 0 && session.rounds >= *count || *untilScore > 0 && session.score >= *untilScore || sprint.over {
+		fmt.Println("The saved session is already over")
+		if !sprint.over {
+			fmt.Println("To go on with it, give a higher -count or -until-score")
+		}
+		os.Exit(0)
+	}
+
+	if last := len(scores.Sessions) - 1; !resumed.Summarized.IsZero() && last >= 0 && scores.Sessions[last].Time.Equal(resumed.Summarized) {
+		scores.Sessions = scores.Sessions[:last]
+	}
+
+	fmt.Printf("Resuming the session in %s mode after %d %s\n", *mode, session.rounds, pluralize("round", session.rounds))
+}
+
+// Writes the session to the path given with -save-session, if any, for it to be resumed with -load-session.
+// If the summary of the session was just added to the scores, as it is on quitting, that is noted to take it back on resuming.
+func saveSession(summarized bool) {
+	if *saveSessionTo == "" {
+		return
+	}
+
+	saved := savedSession{
+		Version:        sessionFormat,
+		Mode:           *mode,
+		Count:          *count,
+		UntilScore:     *untilScore,
+		Seed:           randIntSrc.seed,
+		Draws:          randIntSrc.draws,
+		RecentRandInts: recentRandInts,
+		Rounds:         session.rounds,
+		Results:        saveResults(session.results),
+		Served:         session.served,
+		LastServed:     session.lastServed,
+		PerfectStreak:  session.perfectStreak,
+		Score:          session.score,
+	}
+	for _, round := range session.mistakes {
+		saved.Mistakes = append(saved.Mistakes, savedReview{round.text, saveResult(round.result)})
+	}
+
+	switch *mode {
+	case "drill":
+		saved.Drill = &savedDrill{drill.text, drill.attempts, saveResult(drill.best)}
+	case "consistency":
+		saved.Consistency = &savedConsistency{consistency.text, saveResults(consistency.runs)}
+	case "sprint":
+		saved.Sprint = &savedSprint{sprint.texts, sprint.stage, sprint.budget, sprint.over}
+	}
+
+	if summarized && len(session.results) > 0 {
+		saved.Summarized = scores.Sessions[len(scores.Sessions)-1].Time
+	}
+
+	content, err := json.Marshal(saved)
+	if err == nil {
+		err = ioutil.WriteFile(*saveSessionTo, content, 0644)
+	}
+	if err != nil {
+		fmt.Println("Failed to save the session:", err)
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Everything about the player that is saved locally and loaded on start.
+type Scores struct {
+	// Holds the record corresponding to the respective text.
+	Records map[string]*Record `json:"records"`
+	// Texts the player marked to review later.
+	Favorites []string `json:"favorites,omitempty"`
+	// Holds how often each character was typed wrong or left out.
+	Misses map[string]int `json:"misses,omitempty"`
+	// Holds how often each character was typed, to tell how the keys were spread across the fingers.
+	Keys map[string]int `json:"keys,omitempty"`
+	// Summaries of the most recent sessions, oldest first.
+	Sessions []SessionSummary `json:"sessions,omitempty"`
+	// Holds when each achievement that was unlocked was unlocked. See achievements.
+	Achievements map[string]time.Time `json:"achievements,omitempty"`
+	// Holds when each speed milestone in WPM that was reached was first reached. See milestones.
+	Milestones map[int]time.Time `json:"milestones,omitempty"`
+}
+
+// This is saved locally and loaded on start.
+var scores Scores
+
+// The ways saving and loading the scores can fail, which the errors returned wrap.
+var (
+	// The scores file exists but can't be read.
+	ErrScoresUnreadable = errors.New("the scores file can't be read")
+	// The scores file was read but doesn't hold valid scores.
+	ErrScoresCorrupt = errors.New("the scores file is corrupt")
+	// The scores file can't be written.
+	ErrScoresUnwritable = errors.New("the scores file can't be written")
+)
+
+// The file the scores of the default profile are saved to.
+const scoresFile = "scores.json"
+
+// Gets the file the scores of the current profile are saved to.
+// The default profile uses scores.json in the working directory,
+// other profiles use scores-<profile>.json in the configuration directory.
+func scoresPath() string {
+	if *profile == "" {
+		return scoresFile
+	}
+	return filepath.Join(configDir(), "scores-"+*profile+".json")
+}
+
+// Gets the directory typer keeps its files in, apart from the scores of the default profile.
+func configDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "typer")
+}
+
+// Matches the profile names that are safe to use in a file name.
+var validProfileName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Reports whether scores have been saved before.
+func scoresFileExists() bool {
+	_, err := os.Stat(scoresPath())
+	return err == nil
+}
+
+// Where the scores are saved for the rest of the session because the scores file can't be written.
+// This is empty if the scores are saved to the scores file as usual.
+var fallbackPath string
+
+// Whether the scores are only kept in memory for the rest of the session
+// because neither the scores file nor the fallback can be written.
+var memoryOnly bool
+
+// Checks that the file can be written without changing it,
+// by opening it for writing if it exists and by creating and removing another file next to it.
+// The error wraps ErrScoresUnwritable.
+func checkWritable(path string) (err error) {
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("%w: %v", ErrScoresUnwritable, err)
+	}
+
+	if file, err := os.OpenFile(path, os.O_WRONLY, 0); err == nil {
+		file.Close()
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("%w: %v", ErrScoresUnwritable, err)
+	}
+
+	file, err := ioutil.TempFile(dir, ".typer-")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrScoresUnwritable, err)
+	}
+	file.Close()
+	if err = os.Remove(file.Name()); err != nil {
+		return fmt.Errorf("%w: %v", ErrScoresUnwritable, err)
+	}
+	return
+}
+
+// Warns once that the scores file can't be written and saves the scores elsewhere for the session:
+// to a temporary directory or, if that can't be written either, nowhere.
+func useFallback(err error) {
+	fmt.Println("Scores can't be saved:", err)
+
+	fallback := filepath.Join(os.TempDir(), "typer", filepath.Base(scoresPath()))
+	if checkWritable(fallback) == nil {
+		fallbackPath = fallback
+		fmt.Println("They will be saved to", fallback, "for this session instead")
+	} else {
+		memoryOnly = true
+		fmt.Println("They will only be kept in memory for this session")
+	}
+}
+
+func newScores() Scores {
+	return Scores{Records: make(map[string]*Record), Misses: make(map[string]int), Keys: make(map[string]int)}
+}
+
+// Saves the scores to a local file.
+// The history is trimmed first. See trimHistory.
+// If the scores file can't be written, the scores are saved to the fallback path or not at all. See useFallback.
+// Errors writing the file wrap ErrScoresUnwritable.
+func (scores Scores) Save() (err error) {
+	if memoryOnly {
+		return
+	}
+
+	scoresJson, err := scores.encode()
+
+	if err != nil {
+		return
+	}
+
+	path := scoresPath()
+	if fallbackPath != "" {
+		path = fallbackPath
+	}
+
+	if *profile != "" || fallbackPath != "" {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrScoresUnwritable, err)
+		}
+	}
+
+	perm := os.FileMode(0644) // Read write permissions
+	err = ioutil.WriteFile(path, scoresJson, perm)
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrScoresUnwritable, err)
+	}
+
+	return
+}
+
+// Encodes the scores as they are saved, indented with -pretty and compressed with -gzip.
+// The history is trimmed first. See trimHistory.
+func (scores Scores) encode() (scoresJson []byte, err error) {
+	scores.trimHistory()
+
+	if *pretty {
+		scoresJson, err = json.MarshalIndent(scores, "", "\t")
+	} else {
+		scoresJson, err = json.Marshal(scores)
+	}
+
+	if err != nil {
+		return
+	}
+
+	if *gzipped {
+		scoresJson, err = compress(scoresJson)
+	}
+
+	return
+}
+
+// Loads the scores from a local file.
+// If there is no such file yet, there is nothing to load and no error.
+// Otherwise, the error wraps ErrScoresUnreadable or ErrScoresCorrupt.
+func (scores *Scores) Load() (err error) {
+	return scores.loadFile(scoresPath())
+}
+
+// Loads the scores from the given file like Load.
+func (scores *Scores) loadFile(path string) (err error) {
+	scoresJson, err := ioutil.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%w: %v", ErrScoresUnreadable, err)
+	}
+
+	if bytes.HasPrefix(scoresJson, gzipMagic) {
+		scoresJson, err = decompress(scoresJson)
+
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrScoresCorrupt, err)
+		}
+	}
+
+	err = json.Unmarshal(scoresJson, scores)
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrScoresCorrupt, err)
+	}
+
+	return
+}
+
+// Moves the corrupt scores file out of the way so that the game can start afresh, keeping it for the player to look into.
+// It gets the path it was moved to.
+func setAsideScores() (path string, err error) {
+	path = scoresPath() + ".corrupt"
+	err = os.Rename(scoresPath(), path)
+
+	return
+}
+
+// The bytes every gzip stream starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Compresses the data using gzip.
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompresses gzip-compressed data.
+func decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ioutil.ReadAll(reader)
+}
+
+// Decodes the scores, also accepting the older formats: first, the file held nothing but a map of texts to highscores,
+// then those highscores were kept under "highscores" next to the other fields.
+func (scores *Scores) UnmarshalJSON(data []byte) (err error) {
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+
+	if err != nil {
+		return
+	}
+
+	type plainScores Scores // avoids recursing into this method
+	_, hasRecords := fields["records"]
+	highscores, hasHighscores := fields["highscores"]
+
+	switch {
+	case hasRecords:
+		err = json.Unmarshal(data, (*plainScores)(scores))
+	case hasHighscores:
+		err = json.Unmarshal(data, (*plainScores)(scores))
+		if err == nil {
+			// Records accept bare highscores.
+			err = json.Unmarshal(highscores, &scores.Records)
+		}
+	default:
+		err = json.Unmarshal(data, &scores.Records)
+	}
+
+	if scores.Records == nil {
+		scores.Records = make(map[string]*Record)
+	}
+	if scores.Misses == nil {
+		scores.Misses = make(map[string]int)
+	}
+	if scores.Keys == nil {
+		scores.Keys = make(map[string]int)
+	}
+
+	return
+}
+
+// Reports whether the text is on the review list.
+func (scores Scores) IsFavorite(text string) bool {
+	for _, favorite := range scores.Favorites {
+		if favorite == text {
+			return true
+		}
+	}
+	return false
+}
+
+// Adds the text to the review list or removes it if it is already on it.
+// Returns whether the text is on the list afterwards.
+func (scores *Scores) ToggleFavorite(text string) bool {
+	for i, favorite := range scores.Favorites {
+		if favorite == text {
+			scores.Favorites = append(scores.Favorites[:i], scores.Favorites[i+1:]...)
+			return false
+		}
+	}
+	scores.Favorites = append(scores.Favorites, text)
+	return true
+}
+
+// Adds the characters of the text that were typed wrong or left out to the heatmap.
+func (scores Scores) RecordMisses(ops []editOp) {
+	for _, char := range missedChars(ops) {
+		scores.Misses[string(char)]++
+	}
+}
+
+// Gets up to n of the characters missed most often, starting with the most missed one.
+// Whitespace is left out, as are keys that aren't a single character, such as from an edited scores file.
+func (scores Scores) MostMissed(n int) []rune {
+	var chars []rune
+	for char := range scores.Misses {
+		if utf8.RuneCountInString(char) != 1 {
+			continue
+		}
+		r := []rune(char)[0]
+		if !unicode.IsSpace(r) {
+			chars = append(chars, r)
+		}
+	}
+
+	sort.Slice(chars, func(i, j int) bool {
+		a, b := scores.Misses[string(chars[i])], scores.Misses[string(chars[j])]
+		if a != b {
+			return a > b
+		}
+		return chars[i] < chars[j]
+	})
+
+	if len(chars) > n {
+		chars = chars[:n]
+	}
+	return chars
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// Checks that a missing scores file loads as no scores and that a file that can't be loaded says why.
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content []byte) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want error
+	}{
+		{"missing", filepath.Join(dir, "missing.json"), nil},
+		{"valid", write("valid.json", []byte(`{"records":{}}`)), nil},
+		{"not JSON", write("text.json", []byte("not json")), ErrScoresCorrupt},
+		{"cut off", write("cut.json", []byte(`{"records":{`)), ErrScoresCorrupt},
+		{"bad gzip", write("bad.json.gz", append(append([]byte{}, gzipMagic...), "not gzip"...)), ErrScoresCorrupt},
+		{"directory", dir, ErrScoresUnreadable},
+	}
+	for _, test := range tests {
+		scores := newScores()
+		err := scores.loadFile(test.path)
+		if test.want == nil && err != nil || test.want != nil && !errors.Is(err, test.want) {
+			t.Errorf("%s: loadFile = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+// Checks that a scores file that can't be written is told apart from one that can.
+func TestCheckWritable(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkWritable(filepath.Join(dir, "scores.json")); err != nil {
+		t.Errorf("checkWritable of a new file = %v, want nil", err)
+	}
+	if err := checkWritable(file); err != nil {
+		t.Errorf("checkWritable of an existing file = %v, want nil", err)
+	}
+	// A directory can't be made inside a file, which fails even for root, unlike permissions.
+	if err := checkWritable(filepath.Join(file, "scores.json")); !errors.Is(err, ErrScoresUnwritable) {
+		t.Errorf("checkWritable of a file inside a file = %v, want %v", err, ErrScoresUnwritable)
+	}
+	if err := checkWritable(dir); !errors.Is(err, ErrScoresUnwritable) {
+		t.Errorf("checkWritable of a directory = %v, want %v", err, ErrScoresUnwritable)
+	}
+}
+
+// Checks that a round with mistakes breaks the streak rather than being played during it.
+func TestStreakWith(t *testing.T) {
+	defer func(streak int) { session.perfectStreak = streak }(session.perfectStreak)
+	session.perfectStreak = 3
+
+	if got := streakWith(Result{}); got != 4 {
+		t.Errorf("streakWith of a perfect round = %d, want 4", got)
+	}
+	if got := streakWith(Result{distance: 1}); got != 0 {
+		t.Errorf("streakWith of a round with mistakes = %d, want 0", got)
+	}
+}
+
+// Checks that an attempt only beats the highscore of the -distance algorithm it was measured with.
+func TestKeepBestByAlgorithm(t *testing.T) {
+	record := Record{}
+	record.keepBest(Attempt{Score: 800})
+	record.keepBest(Attempt{Score: 900, Algorithm: "damerau"})
+	record.keepBest(Attempt{Score: 700, Algorithm: defaultDistance})
+
+	if record.highscore(defaultDistance) != 800 {
+		t.Errorf("the %s highscore is %d, want 800", defaultDistance, record.highscore(defaultDistance))
+	}
+	if record.highscore("damerau") != 900 {
+		t.Errorf("the damerau highscore is %d, want 900", record.highscore("damerau"))
+	}
+}
+
+// Checks that keys that aren't a single character, as a scores file edited by hand can have, are left out of the finger workload.
+func TestPrintFingerWorkloadOddKeys(t *testing.T) {
+	printFingerWorkload(map[string]int{"": 3, "ab": 2, "f": 1}, "qwerty")
+}
+
+// Checks that misses that aren't a single character, as a scores file edited by hand can have, are left out of the most missed characters.
+func TestMostMissedOddKeys(t *testing.T) {
+	scores := newScores()
+	scores.Misses = map[string]int{"": 5, "ab": 4, "x": 1}
+	if missed := scores.MostMissed(3); string(missed) != "x" {
+		t.Errorf("MostMissed = %q, want %q", string(missed), "x")
+	}
+}
+
+// Checks that a sloppy attempt doesn't set the fastest time, however fast it was.
+func TestKeepBestFastest(t *testing.T) {
+	record := Record{}
+	record.keepBest(Attempt{Seconds: 10, Accuracy: 95})
+	record.keepBest(Attempt{Seconds: 1, Accuracy: 20})
+
+	if record.Fastest != 10 {
+		t.Errorf("the fastest time is %v, want 10", record.Fastest)
+	}
+}
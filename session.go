@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The rounds played since the game was started.
+var session struct {
+	// How many rounds were played.
+	rounds int
+	// The results of the rounds that count toward the stats.
+	results []Result
+	// How many times each text was served.
+	served map[string]int
+	// The text served last.
+	lastServed string
+	// How many rounds in a row were typed without any mistakes so far.
+	perfectStreak int
+	// The total score of the rounds that count toward the stats.
+	score int
+	// With -review, the rounds with the most mistakes so far, the most first, to be reviewed at the end of the session.
+	// Only the maxReviewed rounds with the most mistakes are kept.
+	mistakes []reviewedRound
+}
+
+// A round kept to be reviewed at the end of the session.
+type reviewedRound struct {
+	text   string
+	result Result
+}
+
+// How many of the rounds with the most mistakes are kept and shown at the end of a session with -review.
+const maxReviewed = 5
+
+// Keeps the round to be reviewed at the end of the session if it is one of those with the most mistakes so far.
+func keepForReview(text string, result Result) {
+	if result.distance == 0 {
+		return
+	}
+
+	i := sort.Search(len(session.mistakes), func(i int) bool {
+		return session.mistakes[i].result.distance < result.distance
+	})
+	if i == maxReviewed {
+		return
+	}
+
+	session.mistakes = append(session.mistakes, reviewedRound{})
+	copy(session.mistakes[i+1:], session.mistakes[i:])
+	session.mistakes[i] = reviewedRound{text, result}
+	if len(session.mistakes) > maxReviewed {
+		session.mistakes = session.mistakes[:maxReviewed]
+	}
+}
+
+// Shows the rounds of the session with the most mistakes, each with the text, the input and where they differ.
+func printReview() {
+	if len(session.mistakes) == 0 {
+		return
+	}
+
+	fmt.Println("Your rounds with the most mistakes this session:")
+	for i, round := range session.mistakes {
+		fmt.Printf("\n%d. Off by %d %s\n", i+1, round.result.distance, pluralize("character", round.result.distance))
+		fmt.Println("The text was:")
+		fmt.Println(prefix + expandTabs(strings.ReplaceAll(round.text, "\n", "\n"+prefix)))
+		fmt.Println("You typed:")
+		fmt.Println(prefix + expandTabs(strings.ReplaceAll(round.result.input, "\n", "\n"+prefix)))
+		printDiff(round.result.ops)
+	}
+	fmt.Println()
+}
+
+// Records that the text was served to be typed.
+func serve(text string) {
+	if session.served == nil {
+		session.served = make(map[string]int)
+	}
+	session.served[text]++
+	session.lastServed = text
+}
+
+// Prints how many texts of the pool were served how many times this session.
+func printServed() {
+	texts := make(map[int]int) // the number of texts served a number of times
+	for _, entry := range pool() {
+		texts[session.served[entry.text]]++
+	}
+
+	var times []int
+	for served := range texts {
+		times = append(times, served)
+	}
+	sort.Ints(times)
+
+	var parts []string
+	for _, served := range times {
+		parts = append(parts, fmt.Sprintf("%d %s %d %s",
+			texts[served], pluralize("text", texts[served]), served, pluralize("time", served)))
+	}
+	fmt.Println("Served:", strings.Join(parts, ", "))
+}
+
+// A summary of a session that is saved to compare the next session against.
+type SessionSummary struct {
+	Time     time.Time `json:"time"`
+	Rounds   int       `json:"rounds"`
+	WPM      float64   `json:"wpm"`
+	Accuracy float64   `json:"accuracy"`
+}
+
+// How many session summaries are kept.
+const maxSessions = 100
+
+// Summarizes the rounds of the session so far.
+func summarizeSession() SessionSummary {
+	summary := SessionSummary{Time: now(), Rounds: len(session.results)}
+	for _, result := range session.results {
+		summary.WPM += result.wpm
+		summary.Accuracy += result.accuracy
+	}
+	if summary.Rounds > 0 {
+		summary.WPM /= float64(summary.Rounds)
+		summary.Accuracy /= float64(summary.Rounds)
+	}
+	return summary
+}
+
+// Prints a summary of the session compared to the previous one and saves it.
+// The rounds to review are printed even if none of them counted, such as in learn mode.
+func endSession() {
+	if *review {
+		printReview()
+	}
+
+	if len(session.results) == 0 {
+		return
+	}
+
+	summary := summarizeSession()
+	printSessionSummary(summary)
+	printWPMHistogram(session.results)
+
+	scores.Sessions = append(scores.Sessions, summary)
+	if len(scores.Sessions) > maxSessions {
+		scores.Sessions = scores.Sessions[len(scores.Sessions)-maxSessions:]
+	}
+}
+
+// Prints the summary of the session compared to the previous one.
+func printSessionSummary(summary SessionSummary) {
+	fmt.Printf("This session: %d %s, %.1f WPM, %.1f%% accuracy on average\n",
+		summary.Rounds, pluralize("round", summary.Rounds), summary.WPM, summary.Accuracy)
+
+	if *balanced && !generatesTexts() {
+		printServed()
+	}
+
+	if len(scores.Sessions) > 0 {
+		previous := scores.Sessions[len(scores.Sessions)-1]
+		fmt.Printf("%+.1f WPM, %+.1f%% accuracy vs last session\n",
+			summary.WPM-previous.WPM, summary.Accuracy-previous.Accuracy)
+	}
+
+	if *keyboardLayout != "" {
+		printFingerWorkload(sessionKeys(), *keyboardLayout)
+	}
+}
+
+// Saves the scores unless -no-save is given and prints the summary of the session so far for a player who seems to have stepped away.
+// With -idle-action exit, the session is ended instead.
+func stepAway() {
+	fmt.Println("\nYou seem to have stepped away")
+
+	if *idleAction == "exit" {
+		quit()
+	}
+
+	if len(session.results) > 0 {
+		printSessionSummary(summarizeSession())
+	}
+
+	if *noSave {
+		fmt.Println("Press Enter to go on")
+	} else if err := saveScores(); err != nil {
+		fmt.Println("Failed to save scores:", err)
+	} else {
+		fmt.Println("Your scores were saved. Press Enter to go on")
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Reports whether a simulated player plays instead of a real one.
+func simulating() bool {
+	return *simulateWPM > 0
+}
+
+// The mistakes of the simulated player.
+var simulationRng *rand.Rand
+
+// Sets up the game for the simulated player: the texts are chosen with -simulate-seed and nothing is saved,
+// so that the simulated rounds never mix with real ones.
+func setUpSimulation() {
+	rng = rand.New(rand.NewSource(*simulateSeed))
+	simulationRng = rand.New(rand.NewSource(*simulateSeed))
+	*noSave = true
+
+	fmt.Printf("Simulating a player typing at %g WPM. This is not a real game and nothing is saved.\n", *simulateWPM)
+}
+
+// Gets what a player makes of the text who types each character wrong with the given chance,
+// typing a letter next to it in the alphabet instead.
+func simulateInput(text string, errorRate float64, random *rand.Rand) string {
+	input := []rune(text)
+	for i, r := range input {
+		if random.Float64() >= errorRate {
+			continue
+		}
+		if r == 'z' || r == 'Z' {
+			input[i] = r - 1
+		} else {
+			input[i] = r + 1
+		}
+	}
+	return string(input)
+}
+
+// Types the text as the simulated player, one key at a time at -simulate WPM, showing each key as it is typed.
+func simulateTyping(textToType string) rawInput {
+	input := []rune(simulateInput(textToType, *simulateErrors, simulationRng))
+	interval := time.Duration(float64(time.Minute) / (*simulateWPM * 5))
+
+	if *rawMode && canRedrawLines() {
+		renderRawLine(nil, textToType, "")
+	}
+
+	var captured rawInput
+	start := now()
+	for i := range input {
+		sleep(interval)
+		captured.keyTimes = append(captured.keyTimes, now().Sub(start))
+		captured.keystrokes++
+
+		if *rawMode && canRedrawLines() {
+			renderRawLine(input[:i+1], textToType, "")
+		} else {
+			fmt.Print(string(input[i]))
+		}
+	}
+
+	captured.text = string(input)
+	return captured
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A source of texts that the texts of a round are taken from in proportion to its weight.
+type textSource struct {
+	name   string
+	weight int
+	// The texts of the source. This is nil if the source generates its texts.
+	texts []textEntry
+}
+
+// The sources given with -sources. This is empty if the texts are all taken from one pool.
+var sources []textSource
+
+// The names of the sources that can be given with -sources and what they are.
+var sourceNames = []struct{ name, description string }{
+	{"builtin", "the built-in texts"},
+	{"texts", "the texts in the -texts file or at the -url"},
+	{"words", "random words"},
+}
+
+// Loads the sources given as a comma-separated list of name:weight pairs such as "builtin:50,texts:30,words:20",
+// out of the built-in texts and those of the -texts file or the -url.
+// The texts of each source are filtered like any others and the pool becomes all of them taken together.
+func loadSources(spec string, builtin, fileTexts []textEntry) (report filterReport, err error) {
+	var pooled []textEntry
+	for _, field := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(field), ":", 2)
+		if len(parts) != 2 {
+			return report, fmt.Errorf("invalid source %q: expected name:weight", field)
+		}
+
+		weight, err := strconv.Atoi(parts[1])
+		if err != nil || weight <= 0 {
+			return report, fmt.Errorf("invalid weight %q of source %s", parts[1], parts[0])
+		}
+
+		source := textSource{name: parts[0], weight: weight}
+		switch source.name {
+		case "builtin":
+			source.texts = builtin
+		case "texts":
+			if *textsFile == "" && *textsURL == "" {
+				return report, fmt.Errorf("the texts source needs a file given with -texts or a URL given with -url")
+			}
+			source.texts = fileTexts
+		case "words":
+		default:
+			return report, fmt.Errorf("unknown source %q", source.name)
+		}
+
+		if source.texts != nil {
+			var sourceReport filterReport
+			source.texts, sourceReport = filterTexts(source.texts)
+			report.empty += sourceReport.empty
+			report.duplicates += sourceReport.duplicates
+			report.tooShort += sourceReport.tooShort
+			report.tooLong += sourceReport.tooLong
+			report.untagged += sourceReport.untagged
+
+			if len(source.texts) == 0 {
+				return report, fmt.Errorf("no texts of the %s source are left to type", source.name)
+			}
+			pooled = append(pooled, source.texts...)
+		}
+
+		sources = append(sources, source)
+	}
+
+	if len(pooled) == 0 {
+		return report, fmt.Errorf("at least one source other than words is needed")
+	}
+
+	// Texts in more than one source are only pooled once.
+	text, _ = filterTexts(pooled)
+
+	return
+}
+
+// Chooses a source by weight and gets the next text from it.
+func nextSourceText() textEntry {
+	total := 0
+	for _, source := range sources {
+		total += source.weight
+	}
+
+	pick := rng.Intn(total)
+	source := sources[0]
+	for _, source = range sources {
+		if pick < source.weight {
+			break
+		}
+		pick -= source.weight
+	}
+
+	if source.texts == nil {
+		return textEntry{text: generateText(nil)}
+	}
+
+	entry := chooseText(source.texts)
+	serve(entry.text)
+	return entry
+}
+
+// Prints how the texts are mixed from the sources.
+func printSourceMix() {
+	total := 0
+	for _, source := range sources {
+		total += source.weight
+	}
+
+	var mix []string
+	for _, source := range sources {
+		share := fmt.Sprintf("%.0f%% %s", float64(source.weight)/float64(total)*100, source.name)
+		if source.texts != nil {
+			share += fmt.Sprintf(" (%d %s)", len(source.texts), pluralize("text", len(source.texts)))
+		}
+		mix = append(mix, share)
+	}
+	fmt.Println("Mixing texts from:", strings.Join(mix, ", "))
+}
+
+// Describes the sources for the usage message.
+func describeSources() string {
+	var descriptions []string
+	for _, source := range sourceNames {
+		descriptions = append(descriptions, source.name+" for "+source.description)
+	}
+	return "mix texts from several sources by weight, such as builtin:50,texts:30,words:20, out of " + strings.Join(descriptions, ", ")
+}
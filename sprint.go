@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// The least accuracy in percent a text has to be typed with in sprint mode to count as done,
+// so that it can't be beaten by just pressing Enter.
+const sprintAccuracy = 90
+
+// The state of sprint mode.
+//
+// A sprint goes through the texts from the longest to the shortest, one stage each.
+// Each text has to be typed within a time budget: the time it takes to type it at -sprint-wpm at the first stage,
+// with the speed going up by -sprint-step at each stage after it. As both the texts get shorter and the speed goes up,
+// the budget shrinks from stage to stage. The sprint ends at the first text that isn't typed within its budget
+// with at least sprintAccuracy, or once all texts are done.
+var sprint struct {
+	// The texts of the stages, longest first. This is nil until the sprint starts.
+	texts []string
+	// The stage being played, from 0.
+	stage int
+	// The time the text of the stage has to be typed in.
+	budget time.Duration
+	// Whether the sprint is over.
+	over bool
+}
+
+// Gets the speed in WPM the text of the stage has to be typed at.
+func sprintWPM(stage int) float64 {
+	return *sprintStartWPM + float64(stage)**sprintStep
+}
+
+// Gets the text of the next stage of the sprint and works out its time budget.
+func nextSprintText() string {
+	if sprint.texts == nil {
+		sprint.texts = textsOf(pool())
+		sort.SliceStable(sprint.texts, func(i, j int) bool {
+			return len([]rune(sprint.texts[i])) > len([]rune(sprint.texts[j]))
+		})
+	}
+
+	text := sprint.texts[sprint.stage]
+	wpm := sprintWPM(sprint.stage)
+	minutes := float64(textLength(text)) / 5 / wpm
+	sprint.budget = time.Duration(minutes * float64(time.Minute))
+
+	fmt.Printf("Stage %d of %d: type it within %.1fs (%.0f WPM)\n",
+		sprint.stage+1, len(sprint.texts), sprint.budget.Seconds(), wpm)
+	return text
+}
+
+// Decides after a round in sprint mode whether the sprint goes on.
+func continueSprint(result Result) {
+	switch {
+	case result.totalTime > sprint.budget:
+		fmt.Printf("Time's up! It had to be typed within %.1fs.\n", sprint.budget.Seconds())
+	case result.accuracy < sprintAccuracy:
+		fmt.Printf("Not accurate enough! It takes at least %d%% accuracy.\n", sprintAccuracy)
+	default:
+		sprint.stage++
+		if sprint.stage == len(sprint.texts) {
+			fmt.Println("You finished the sprint! All", len(sprint.texts), pluralize("stage", len(sprint.texts)), "done.")
+			sprint.over = true
+		} else {
+			fmt.Printf("Stage cleared with %.1fs to spare!\n", (sprint.budget - result.totalTime).Seconds())
+		}
+		return
+	}
+
+	fmt.Printf("Your sprint ended at stage %d of %d, having cleared %d %s.\n",
+		sprint.stage+1, len(sprint.texts), sprint.stage, pluralize("stage", sprint.stage))
+	sprint.over = true
+}
+
+// Gets how much of the time budget of the stage is left after the given time, for the status line in raw mode.
+func sprintTimeLeft(elapsed time.Duration) string {
+	left := sprint.budget - elapsed
+	if left < 0 {
+		left = 0
+	}
+	return fmt.Sprintf("Time left: %.1fs", left.Seconds())
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Prints the records of all texts typed so far.
+func printStats() {
+	if len(scores.Records) == 0 {
+		fmt.Println("No texts typed yet")
+		return
+	}
+
+	var texts []string
+	for text := range scores.Records {
+		texts = append(texts, text)
+	}
+	sort.Strings(texts)
+
+	for _, text := range texts {
+		record := scores.Records[text]
+		fmt.Println(text)
+
+		difficulty := record.Difficulty
+		if difficulty == "" {
+			difficulty = "unrated"
+		}
+		fmt.Printf("  Highscore: %d, attempts: %d, difficulty: %s\n", record.highscore(*distanceMetric), len(record.Attempts), difficulty)
+
+		if entry, ok := findText(text); ok && entry.source.name != "" {
+			fmt.Println("  Source:", entry.source)
+		}
+
+		if record.Fastest > 0 {
+			fmt.Printf("  Fastest: %.1fs\n", record.Fastest)
+		}
+
+		if clean := record.cleanRecord(); clean > 0 {
+			fmt.Printf("  Clean record: %.1fs (%.1f WPM)\n", clean, getWPM(textLength(text), time.Duration(clean*float64(time.Second))))
+		} else {
+			fmt.Println("  Clean record: not yet")
+		}
+
+		if len(record.Attempts) > 0 {
+			fmt.Println("  Accuracy trend:", accuracyTrend(record.Attempts))
+		}
+
+		if distractors, perfect := record.distractors(); distractors > 0 {
+			fmt.Printf("  Distractors: %d, typed perfectly: %d\n", distractors, perfect)
+		}
+	}
+
+	printTagStats()
+	printTrends()
+	printHourStats()
+
+	layout := *keyboardLayout
+	if layout == "" {
+		layout = defaultLayout
+	}
+	printFingerWorkload(scores.Keys, layout)
+}
+
+// Prints the stats of the texts with each tag taken together.
+func printTagStats() {
+	var tags []string
+	texts := make(map[string][]string)
+	for _, entry := range text {
+		if _, ok := scores.Records[entry.text]; !ok {
+			continue
+		}
+		for _, tag := range entry.tags {
+			if texts[tag] == nil {
+				tags = append(tags, tag)
+			}
+			texts[tag] = append(texts[tag], entry.text)
+		}
+	}
+	if len(tags) == 0 {
+		return
+	}
+	sort.Strings(tags)
+
+	fmt.Println("By tag:")
+	for _, tag := range tags {
+		attempts := 0
+		var wpm, accuracy float64
+		for _, text := range texts[tag] {
+			for _, attempt := range scores.Records[text].Attempts {
+				attempts++
+				wpm += attempt.WPM
+				accuracy += attempt.Accuracy
+			}
+		}
+
+		fmt.Printf("  #%s: %d %s, %d %s", tag, len(texts[tag]), pluralize("text", len(texts[tag])), attempts, pluralize("attempt", attempts))
+		if attempts > 0 {
+			fmt.Printf(", %.1f WPM and %.1f%% accuracy on average", wpm/float64(attempts), accuracy/float64(attempts))
+		}
+		fmt.Println()
+	}
+}
+
+// Prints the texts that were never typed without any mistakes, including those never typed at all.
+func printRemaining() {
+	var remaining []string
+	texts := pool()
+	for _, entry := range texts {
+		if record, ok := scores.Records[entry.text]; !ok || !record.perfected() {
+			remaining = append(remaining, entry.text)
+		}
+	}
+
+	if len(remaining) == 0 {
+		fmt.Println("You typed every text perfectly!")
+		return
+	}
+
+	fmt.Printf("%d of %d %s yet to be typed perfectly:\n", len(remaining), len(texts), pluralize("text", len(texts)))
+	for _, text := range remaining {
+		fmt.Println(text)
+	}
+}
+
+// How many of the most recent attempts the accuracy trend shows.
+const trendLength = 20
+
+// The fewest attempts for which the trend is drawn as a sparkline rather than listed.
+const minSparklineLength = 3
+
+// Shows the accuracy of the most recent attempts, oldest first.
+func accuracyTrend(attempts []Attempt) string {
+	if len(attempts) > trendLength {
+		attempts = attempts[len(attempts)-trendLength:]
+	}
+
+	values := make([]float64, len(attempts))
+	for i, attempt := range attempts {
+		values[i] = attempt.Accuracy
+	}
+
+	if len(values) < minSparklineLength {
+		var listed []string
+		for _, value := range values {
+			listed = append(listed, fmt.Sprintf("%.0f%%", value))
+		}
+		return strings.Join(listed, ", ")
+	}
+
+	return fmt.Sprintf("%s (%.0f%% to %.0f%%)", sparkline(values, 0, 100), values[0], values[len(values)-1])
+}
+
+// The characters of a sparkline, from lowest to highest.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Draws the values, which lie between min and max, as a line of bars of varying height.
+func sparkline(values []float64, min, max float64) string {
+	var line strings.Builder
+	for _, value := range values {
+		level := 0
+		if max > min {
+			level = int((value - min) / (max - min) * float64(len(sparks)-1))
+		}
+		if level < 0 {
+			level = 0
+		} else if level >= len(sparks) {
+			level = len(sparks) - 1
+		}
+		line.WriteRune(sparks[level])
+	}
+	return line.String()
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// A command that does something other than playing, run as "typer <name> [arguments]".
+type subcommand struct {
+	name        string
+	description string
+	run         func(args []string) error
+	// Whether the command types texts of the pool, so that it can't run without any.
+	playsTexts bool
+}
+
+var subcommands []subcommand
+
+func init() {
+	subcommands = []subcommand{
+		{"profiles", "list the known profiles", listProfiles, false},
+		{"rekey", "move the record of a text to its edited version: rekey \"old text\" \"new text\"", rekey, false},
+		{"config", "export the settings given on the command line and imported before, or import settings to be used from now on: config export [file], config import file", runConfig, false},
+		{"merge", "merge two scores files, such as from different machines: merge a.json b.json -o out.json", mergeScoresFiles, false},
+		{"achievements", "list the achievements and which of them you unlocked", listAchievements, false},
+		{"benchmark", "run rounds without a player and report the results deterministically", runBenchmark, true},
+		{"repair", "check the scores file for inconsistencies and write a repaired version, keeping the original as a backup", repairScores, false},
+	}
+}
+
+// Reports whether the named subcommand types texts of the pool.
+func subcommandPlaysTexts(name string) bool {
+	for _, subcommand := range subcommands {
+		if subcommand.name == name {
+			return subcommand.playsTexts
+		}
+	}
+	return false
+}
+
+// Runs the subcommand named by the first argument and exits.
+func runSubcommand(args []string) {
+	for _, subcommand := range subcommands {
+		if subcommand.name == args[0] {
+			if err := subcommand.run(args[1:]); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Println("Unknown command:", args[0])
+	fmt.Println("Commands:")
+	for _, subcommand := range subcommands {
+		fmt.Printf("  %-12s %s\n", subcommand.name, subcommand.description)
+	}
+	os.Exit(1)
+}
+
+// Lists the profiles that have saved scores.
+func listProfiles(args []string) error {
+	if _, err := os.Stat(scoresFile); err == nil {
+		fmt.Println("(default)")
+	}
+
+	paths, err := filepath.Glob(filepath.Join(configDir(), "scores-*.json"))
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, path := range paths {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "scores-"), ".json")
+		if validProfileName.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Println(name)
+	}
+
+	return nil
+}
+
+// Moves the record of a text to another text, such as after fixing a typo in it,
+// so that its highscore and history are kept.
+func rekey(args []string) (err error) {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: typer rekey \"old text\" \"new text\"")
+	}
+	oldText, newText := args[0], args[1]
+
+	scores = newScores()
+	if err = scores.Load(); err != nil {
+		return
+	}
+
+	record, ok := scores.Records[oldText]
+	if !ok {
+		return fmt.Errorf("no record of the text %q", oldText)
+	}
+	if _, ok := scores.Records[newText]; ok {
+		return fmt.Errorf("the text %q already has a record", newText)
+	}
+
+	scores.Records[newText] = record
+	delete(scores.Records, oldText)
+
+	for i, favorite := range scores.Favorites {
+		if favorite == oldText {
+			scores.Favorites[i] = newText
+		}
+	}
+
+	if err = scores.Save(); err != nil {
+		return
+	}
+
+	fmt.Printf("Moved the record of %q to %q\n", oldText, newText)
+
+	return
+}
@@ -0,0 +1,599 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// The terminal state before entering raw mode.
+// This is nil while the terminal is not in raw mode.
+var rawState *term.State
+
+// Whether the input came from a terminal when the game was started.
+var stdinIsTerminal bool
+
+// Reports whether the terminal the input came from went away, such as when an SSH connection dropped.
+func terminalGone() bool {
+	if !stdinIsTerminal {
+		return false
+	}
+	_, err := term.GetState(int(os.Stdin.Fd()))
+	return err != nil
+}
+
+// Puts the terminal into raw mode so that input can be read key by key.
+func enterRawMode() (err error) {
+	rawState, err = term.MakeRaw(int(os.Stdin.Fd()))
+
+	return
+}
+
+// Restores the terminal to the state it was in before entering raw mode.
+func exitRawMode() {
+	if rawState == nil {
+		return
+	}
+
+	term.Restore(int(os.Stdin.Fd()), rawState)
+	rawState = nil
+}
+
+// Reports whether the output goes to a terminal that lines can be redrawn on, as opposed to a file or a dumb terminal.
+func canRedrawLines() bool {
+	return term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("TERM") != "dumb"
+}
+
+// Resets the color of the text that follows.
+const colorReset = "\x1b[0m"
+
+// Starts showing text with its colors inverted.
+const inverseOn = "\x1b[7m"
+
+// Reports whether the next character to type is highlighted in raw mode.
+func highlightsNext() bool {
+	return (*highlightNext || *mode == "learn") && canRedrawLines()
+}
+
+// Reports whether wrong keys are rejected rather than typed in raw mode.
+func stopsOnError() bool {
+	return *stopOnError || *mode == "learn"
+}
+
+// Gets the name of the key that types the character, as shown in hints.
+func keyName(r rune) string {
+	switch r {
+	case ' ':
+		return "Space"
+	case '\n':
+		return "Enter"
+	case '\t':
+		return "Tab"
+	default:
+		return string(r)
+	}
+}
+
+// Reports whether the output may be colored.
+// Colors are disabled by -no-color, by setting the NO_COLOR environment variable, and when lines can't be redrawn.
+func colorEnabled() bool {
+	return !*noColor && os.Getenv("NO_COLOR") == "" && canRedrawLines()
+}
+
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBackspace = 8
+	keyCtrlK     = 11
+	keyCtrlP     = 16
+	keyTab       = '\t'
+	keyEnter     = '\r'
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// Redraws the line of a raw mode round: the input typed so far followed by the rest of the visible text,
+// with the cursor placed right after the input.
+// If the status is not empty, it is shown on the line below.
+func renderRawLine(input []rune, textToType string, status string) {
+	rest := []rune(visibleText(string(input), textToType))
+	if len(input) < len(rest) {
+		rest = rest[len(input):]
+	} else {
+		rest = nil
+	}
+
+	var line strings.Builder
+	line.WriteString("\r")
+	line.WriteString(prefix)
+	line.WriteString(paintInput(input, []rune(textToType)))
+	if len(rest) > 0 && highlightsNext() {
+		line.WriteString(paint(roleHighlight, string(rest[0])))
+		rest = rest[1:]
+	}
+	line.WriteString(string(rest))
+	line.WriteString("\x1b[K") // clear the rest of the line
+	if status != "" {
+		line.WriteString("\r\n")
+		line.WriteString(status)
+		line.WriteString("\x1b[K\x1b[1A") // clear the rest of the line and move back up
+	}
+	line.WriteString("\r")
+	if column := len(prefix) + len(input); column > 0 {
+		fmt.Fprintf(&line, "\x1b[%dC", column) // move the cursor to the end of the input
+	}
+
+	fmt.Print(line.String())
+}
+
+// Draws a text spanning multiple lines and the input typed over it in raw mode.
+type rawBlock struct {
+	// The row the cursor is on, counting from the first line of the block.
+	cursorRow int
+	// How many lines of text were drawn, not counting the status line.
+	lines int
+}
+
+// Redraws the block: each line of the input typed so far followed by the rest of the corresponding line of the text,
+// with the cursor placed right after the input.
+// If the status is not empty, it is shown below the block, taking as many lines as it has.
+func (block *rawBlock) render(input []rune, textToType string, status string) {
+	var out strings.Builder
+	if block.cursorRow > 0 {
+		fmt.Fprintf(&out, "\x1b[%dA", block.cursorRow) // move the cursor up to the first line
+	}
+	out.WriteString("\r")
+
+	textLines := strings.Split(textToType, "\n")
+	inputLines := strings.Split(string(input), "\n")
+	lines := len(textLines)
+	if len(inputLines) > lines {
+		lines = len(inputLines)
+	}
+
+	for i := 0; i < lines; i++ {
+		if i == 0 {
+			out.WriteString(prefix)
+		} else {
+			out.WriteString("\r\n")
+			out.WriteString(strings.Repeat(" ", len(prefix)))
+		}
+
+		typed := 0
+		if i < len(inputLines) {
+			var textLine []rune
+			if i < len(textLines) {
+				textLine = []rune(textLines[i])
+			}
+			out.WriteString(expandTabs(paintInput([]rune(inputLines[i]), textLine)))
+			typed = len([]rune(inputLines[i]))
+		}
+		if i < len(textLines) {
+			if i == len(inputLines)-1 && highlightsNext() {
+				if runes := []rune(textLines[i]); typed < len(runes) {
+					out.WriteString(paint(roleHighlight, expandTabs(string(runes[typed]))))
+					typed++
+				} else if i < len(textLines)-1 {
+					// A line break is next.
+					out.WriteString(paint(roleHighlight, " "))
+				}
+			}
+			out.WriteString(highlightRest(textLines[i], typed))
+		}
+		out.WriteString("\x1b[K") // clear the rest of the line
+	}
+
+	if status != "" {
+		out.WriteString("\r\n")
+		out.WriteString(status)
+	}
+	out.WriteString("\x1b[J") // clear everything below, which may be left over from a previous drawing
+
+	row := len(inputLines) - 1
+	below := lines - 1 - row
+	if status != "" {
+		below += strings.Count(status, "\n") + 1
+	}
+	if below > 0 {
+		fmt.Fprintf(&out, "\x1b[%dA", below) // move the cursor up to the line being typed
+	}
+	out.WriteString("\r")
+	if column := len(prefix) + len([]rune(expandTabs(inputLines[row]))); column > 0 {
+		fmt.Fprintf(&out, "\x1b[%dC", column) // move the cursor to the end of the input
+	}
+
+	block.cursorRow = row
+	block.lines = lines
+	fmt.Print(out.String())
+}
+
+// Redraws the block without a status line and moves the cursor to its last line once the input is done.
+func (block *rawBlock) finish(input []rune, textToType string) {
+	block.render(input, textToType, "")
+	if below := block.lines - 1 - block.cursorRow; below > 0 {
+		fmt.Printf("\x1b[%dB", below)
+	}
+}
+
+// Clears the status line below the line of a raw mode round.
+func clearStatusLine() {
+	fmt.Print("\x1b[1B\r\x1b[K\x1b[1A")
+}
+
+// How often the line of a raw mode round is redrawn while no key is pressed, if anything on it moves.
+const redrawInterval = 100 * time.Millisecond
+
+// Gets the status shown below the line of a raw mode round.
+func rawStatus(input, target []rune, elapsed time.Duration) string {
+	if !canRedrawLines() {
+		return ""
+	}
+
+	var parts []string
+	if *paceWPM > 0 {
+		parts = append(parts, paceMarker(elapsed))
+	}
+	if *liveWPM {
+		parts = append(parts, fmt.Sprintf("Speed: %.0f WPM", getWPM(len(input), elapsed)))
+	}
+	if *mode == "sprint" {
+		parts = append(parts, sprintTimeLeft(elapsed))
+	}
+	if *showProgress && len(target) > 0 {
+		parts = append(parts, fmt.Sprintf("Progress: %d%%", 100*typedCorrectly(input, target)/len(target)))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// Gets how many characters of the target were typed correctly before the first mistake in the input,
+// which is the position the next character to type is at.
+func typedCorrectly(input, target []rune) int {
+	typed := 0
+	for typed < len(input) && typed < len(target) && input[typed] == target[typed] {
+		typed++
+	}
+	return typed
+}
+
+// Gets a line with a marker below the position of the text that typing at -pace-wpm would have reached.
+func paceMarker(elapsed time.Duration) string {
+	position := int(float64(*paceWPM) * 5 * elapsed.Minutes())
+	return strings.Repeat(" ", len(prefix)+position) + "^ " + strconv.Itoa(*paceWPM) + " WPM"
+}
+
+// What is shown while a round is paused.
+const pausedMessage = "Paused. Press any key to go on"
+
+// What was captured while reading in a line key by key.
+type rawInput struct {
+	text string
+	// How many times a character was erased to correct a mistake.
+	corrections int
+	// How many wrong keys were pressed with -stop-on-error.
+	wrongKeys int
+	// When each key typing a character was pressed, from the start of the round.
+	keyTimes []time.Duration
+	// How many repeated keys were ignored with -filter-chatter.
+	chatter int
+	// The time taken for each word of the text typed.
+	words []wordTime
+	// When the first character of the text was first typed correctly at the start of the input.
+	// This is the zero time if it never was.
+	startedAt time.Time
+	// How many times a wrong character was typed at the start of the input.
+	falseStarts int
+	// How many keys were pressed in total, including corrections.
+	keystrokes int
+	// How long the round was paused with Ctrl+P, in total and before startedAt.
+	paused            time.Duration
+	pausedBeforeStart time.Duration
+	// How far behind the dictation the words were typed on average in dictation mode.
+	dictationLag time.Duration
+}
+
+// Reads in a line key by key while the terminal is in raw mode.
+//
+// With -stop-on-error, a wrong key is counted but not typed,
+// so the input never goes past a character until it is typed correctly.
+func readRaw(textToType string) rawInput {
+	var input []rune
+	// When each character of the input was typed, from the start of the round.
+	var typedAt []time.Duration
+	var captured rawInput
+	target := []rune(textToType)
+	startTime := now()
+
+	// When the round was paused with Ctrl+P. This is the zero time while it isn't paused.
+	var pausedAt time.Time
+
+	// Gets the time since the start of the round, leaving out the time it was paused.
+	elapsed := func() time.Duration {
+		if !pausedAt.IsZero() {
+			return pausedAt.Sub(startTime) - captured.paused
+		}
+		return now().Sub(startTime) - captured.paused
+	}
+
+	// Gets the time the round is scored with so far, which with -fair-start only starts once the text is started right.
+	roundTime := func() time.Duration {
+		if *fairStart && !captured.startedAt.IsZero() {
+			return elapsed() - (captured.startedAt.Sub(startTime) - captured.pausedBeforeStart)
+		}
+		return elapsed()
+	}
+
+	render := renderRawLine
+	finish := func(input []rune, status string) {
+		if status != "" {
+			clearStatusLine()
+		}
+	}
+	if isRightToLeft(textToType) && !strings.Contains(textToType, "\n") {
+		lines := &rawLines{}
+		render = lines.render
+		finish = lines.finish
+	} else if strings.Contains(textToType, "\n") || *mode == "guided" {
+		// The keyboard of guided mode takes several lines below the text, which a block can show.
+		block := &rawBlock{}
+		render = block.render
+		finish = func(input []rune, status string) {
+			block.finish(input, textToType)
+		}
+	}
+
+	// Whether the last key pressed was rejected as wrong.
+	var wrongKeyPressed bool
+
+	// Adds the character to the input unless it is wrong with -stop-on-error.
+	typeRune := func(r rune) {
+		captured.keyTimes = append(captured.keyTimes, elapsed())
+
+		if len(input) == 0 && len(target) > 0 {
+			if r != target[0] {
+				captured.falseStarts++
+			} else if captured.startedAt.IsZero() {
+				captured.startedAt = now()
+			}
+		}
+
+		if stopsOnError() && (len(input) >= len(target) || r != target[len(input)]) {
+			captured.wrongKeys++
+			wrongKeyPressed = true
+			return
+		}
+
+		wrongKeyPressed = false
+		input = append(input, r)
+		typedAt = append(typedAt, elapsed())
+	}
+
+	// A key held back because it may be the first half of the next character of the text,
+	// an accented character typed as two keys that the terminal didn't compose. This is 0 if no key is held back.
+	var heldKey rune
+
+	// The last printable key pressed and when, to detect chatter.
+	var lastRune rune
+	var lastPressedAt time.Time
+
+	// Whether the key pressed last was Enter, to detect a double Enter.
+	var enterPressed bool
+
+	// Gets the status line, which in learn mode hints at the key to press after a wrong one.
+	// In guided mode, the keyboard is shown below it.
+	statusAt := func(elapsed time.Duration) string {
+		status := rawStatus(input, target, elapsed)
+		if *mode == "learn" && wrongKeyPressed && len(input) < len(target) {
+			hint := "Hint: press " + keyName(target[len(input)])
+			if status == "" {
+				return hint
+			}
+			return status + "  " + hint
+		}
+		if keyboard := guidedKeyboard(input, target); keyboard != "" {
+			return status + "\r\n" + keyboard
+		}
+		return status
+	}
+
+	// With -no-echo, the text is drawn as if nothing was typed until the round ends.
+	if *noEcho {
+		draw := render
+		render = func(input []rune, textToType string, status string) {
+			draw(nil, textToType, status)
+		}
+	}
+
+	if *mode == "dictation" {
+		dictation.dictated = 0
+		dictate(textToType, 0)
+	}
+
+	status := statusAt(0)
+	render(input, textToType, status)
+
+	// Ends the input.
+	done := func() rawInput {
+		finish(input, status)
+		captured.text = string(input)
+		captured.words = timeWords(input, typedAt)
+		if *mode == "dictation" {
+			captured.dictationLag = dictationLag(input, typedAt)
+		}
+		return captured
+	}
+
+	ticker := time.NewTicker(redrawInterval)
+	defer ticker.Stop()
+
+	// The beat of the metronome, which never ticks if it is off.
+	var beat <-chan time.Time
+	if metronomeOn() {
+		fmt.Print(bell)
+		beatTicker := time.NewTicker(beatInterval())
+		defer beatTicker.Stop()
+		beat = beatTicker.C
+	}
+
+	for {
+		var r rune
+		var err error
+		select {
+		case key := <-keyPresses():
+			r, err = key.r, key.err
+		case <-ticker.C:
+			if !pausedAt.IsZero() {
+				continue
+			}
+			// In sprint mode, the round ends with what was typed once the time budget of the stage runs out.
+			if *mode == "sprint" && roundTime() > sprint.budget {
+				return done()
+			}
+			dictated := *mode == "dictation" && dictate(textToType, elapsed())
+			if status != "" || dictated {
+				status = statusAt(elapsed())
+				render(input, textToType, status)
+			}
+			continue
+		case <-beat:
+			if pausedAt.IsZero() {
+				fmt.Print(bell)
+			}
+			continue
+		}
+
+		if err != nil {
+			handleInputError(err)
+		}
+
+		// Ctrl+P pauses the round, hiding the text, and any key but Ctrl+C goes on with it without being typed.
+		if r == keyCtrlP && pausedAt.IsZero() {
+			pausedAt = now()
+			render(nil, "", pausedMessage)
+			continue
+		}
+		if !pausedAt.IsZero() && r != keyCtrlC {
+			pause := now().Sub(pausedAt)
+			captured.paused += pause
+			if captured.startedAt.IsZero() {
+				captured.pausedBeforeStart += pause
+			}
+			pausedAt = time.Time{}
+
+			clearStatusLine()
+			status = statusAt(elapsed())
+			render(input, textToType, status)
+			continue
+		}
+
+		// Ctrl+K shows or hides the keyboard in guided mode without being typed.
+		if r == keyCtrlK && *mode == "guided" {
+			keyboardHidden = !keyboardHidden
+			status = statusAt(elapsed())
+			render(input, textToType, status)
+			continue
+		}
+
+		captured.keystrokes++
+
+		afterEnter := enterPressed
+		enterPressed = r == keyEnter || r == '\n'
+
+		// Keys that don't type a character type the held key as it is first.
+		if heldKey != 0 && r != keyBackspace && r != keyDelete && !unicode.IsPrint(r) {
+			typeRune(heldKey)
+			heldKey = 0
+		}
+
+		switch r {
+		case keyEnter, '\n':
+			switch {
+			case *submit == "double-enter" && afterEnter:
+				// The line break typed by the first Enter is taken back.
+				if len(input) > 0 && input[len(input)-1] == '\n' {
+					input = input[:len(input)-1]
+					typedAt = typedAt[:len(typedAt)-1]
+				}
+				return done()
+			case *submit == "enter" && strings.Count(string(input), "\n") >= strings.Count(textToType, "\n"):
+				return done()
+			}
+
+			// Otherwise, Enter types a line break.
+			typeRune('\n')
+		case keyCtrlD:
+			if *submit == "ctrl-d" {
+				return done()
+			}
+		case keyTab:
+			for _, r := range indentation() {
+				typeRune(r)
+			}
+		case keyCtrlC:
+			exitRawMode()
+			fmt.Println()
+
+			scorePartialRound(textToType, input, captured, roundTime())
+
+			quit()
+		case keyBackspace, keyDelete:
+			if heldKey != 0 {
+				heldKey = 0
+				captured.corrections++
+			} else if len(input) > 0 {
+				input = input[:len(input)-1]
+				typedAt = typedAt[:len(typedAt)-1]
+				captured.corrections++
+			}
+		default:
+			if r == utf8.RuneError || !unicode.IsPrint(r) {
+				break
+			}
+
+			// A key repeated faster than anyone could press it again is taken to be stuck, auto-repeating or chattering.
+			pressedAt := now()
+			if *filterChatter && r == lastRune && pressedAt.Sub(lastPressedAt) < *chatterGap {
+				captured.chatter++
+				lastPressedAt = pressedAt
+				break
+			}
+			lastRune, lastPressedAt = r, pressedAt
+
+			// The two keys of an accented character are combined into it before it is compared with the text.
+			if heldKey != 0 {
+				first := heldKey
+				heldKey = 0
+				if composed, ok := compose(first, r); ok && len(input) < len(target) && composed == target[len(input)] {
+					typeRune(composed)
+					break
+				}
+				typeRune(first)
+			}
+			if len(input) < len(target) && startsComposition(r, target[len(input)]) {
+				heldKey = r
+				break
+			}
+
+			typeRune(r)
+		}
+
+		status = statusAt(elapsed())
+		render(input, textToType, status)
+	}
+}
+
+// Reads in a line key by key, putting the terminal into raw mode for the duration.
+func readRawLine(textToType string) rawInput {
+	if err := enterRawMode(); err != nil {
+		fmt.Println("\nFailed to enter raw mode")
+		os.Exit(1)
+	}
+	defer exitRawMode()
+
+	return readRaw(textToType)
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// A text to be typed and what is known about it.
+type textEntry struct {
+	text string
+	// Where the text is from. Its name is empty if this isn't known.
+	source attribution
+	// The tags of the text, which only texts files in the JSON format give. See loadTexts.
+	tags []string
+}
+
+// Makes entries of the texts, of which nothing else is known.
+func plainTexts(texts []string) []textEntry {
+	entries := make([]textEntry, len(texts))
+	for i, text := range texts {
+		entries[i] = textEntry{text: text}
+	}
+	return entries
+}
+
+// Gets the texts of the entries.
+func textsOf(entries []textEntry) []string {
+	texts := make([]string, len(entries))
+	for i, entry := range entries {
+		texts[i] = entry.text
+	}
+	return texts
+}
+
+// Reports whether the text has the tag.
+func (entry textEntry) hasTag(tag string) bool {
+	for _, other := range entry.tags {
+		if other == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Loads the texts to be typed from a file.
+// By default, each line is a text. A file ending in .json holds a list of texts with where they are from and their tags instead,
+// as in [{"text": "A text.", "source": "Author", "url": "https://example.com", "tags": ["quotes", "beginner"]}].
+// With -sentences, the file is read as prose and split into sentences.
+func loadTexts(path string) ([]textEntry, error) {
+	content, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if *mode == "code" {
+		return plainTexts(splitSnippets(string(content))), nil
+	}
+
+	if *sentences {
+		return plainTexts(splitSentences(string(content))), nil
+	}
+
+	if strings.HasSuffix(path, ".json") {
+		return parseTextsJSON(content)
+	}
+
+	var texts []string
+	for _, line := range strings.Split(string(content), "\n") {
+		texts = append(texts, strings.TrimSpace(line))
+	}
+	return plainTexts(texts), nil
+}
+
+// A text as given in a texts file in the JSON format.
+type jsonText struct {
+	Text   string   `json:"text"`
+	Source string   `json:"source,omitempty"`
+	URL    string   `json:"url,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// Parses a texts file in the JSON format.
+func parseTextsJSON(content []byte) ([]textEntry, error) {
+	var texts []jsonText
+	if err := json.Unmarshal(content, &texts); err != nil {
+		return nil, fmt.Errorf("the texts aren't a list of texts in the JSON format: %v", err)
+	}
+
+	entries := make([]textEntry, len(texts))
+	for i, text := range texts {
+		entries[i] = textEntry{
+			text:   strings.TrimSpace(text.Text),
+			source: attribution{strings.TrimSpace(text.Source), text.URL},
+			tags:   text.Tags,
+		}
+	}
+	return entries, nil
+}
+
+// Words that end with a period without ending the sentence.
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "st": true, "jr": true, "sr": true,
+	"vs": true, "etc": true, "e.g": true, "i.e": true, "no": true, "fig": true, "approx": true,
+}
+
+// Reports whether the word, which is followed by a period, is an abbreviation or an initial such as the "J" in "J. R. R. Tolkien".
+func isAbbreviation(word string) bool {
+	word = strings.TrimLeft(word, `"'“‘(`)
+	runes := []rune(word)
+	if len(runes) == 1 && unicode.IsUpper(runes[0]) {
+		return true
+	}
+	return abbreviations[strings.ToLower(word)]
+}
+
+// Splits prose into sentences ending with '.', '!' or '?'.
+// Closing quotes and brackets after the punctuation stay with the sentence,
+// abbreviations don't end a sentence, and line breaks become spaces.
+func splitSentences(prose string) []string {
+	words := strings.Fields(prose)
+
+	var sentences []string
+	var sentence []string
+	for _, word := range words {
+		sentence = append(sentence, word)
+
+		end := strings.TrimRight(word, `"'”’)]`)
+		if end == "" {
+			continue
+		}
+
+		switch end[len(end)-1] {
+		case '!', '?':
+		case '.':
+			if end == word && isAbbreviation(strings.TrimSuffix(end, ".")) {
+				continue
+			}
+		default:
+			continue
+		}
+
+		sentences = append(sentences, strings.Join(sentence, " "))
+		sentence = nil
+	}
+
+	if len(sentence) > 0 {
+		sentences = append(sentences, strings.Join(sentence, " "))
+	}
+
+	return sentences
+}
+
+// How many texts were left out for each reason when filtering them.
+type filterReport struct {
+	empty      int
+	duplicates int
+	tooShort   int
+	tooLong    int
+	// Texts without the -tag.
+	untagged int
+}
+
+// Drops empty and duplicate texts, those not within -min-length and -max-length and those without the -tag.
+func filterTexts(texts []textEntry) ([]textEntry, filterReport) {
+	var report filterReport
+	seen := make(map[string]bool)
+
+	var filtered []textEntry
+	for _, entry := range texts {
+		length := len([]rune(entry.text))
+		switch {
+		case entry.text == "":
+			report.empty++
+		case seen[entry.text]:
+			report.duplicates++
+		case length < *minLength:
+			report.tooShort++
+		case *maxLength > 0 && length > *maxLength:
+			report.tooLong++
+		case *tag != "" && !entry.hasTag(*tag):
+			report.untagged++
+		default:
+			seen[entry.text] = true
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered, report
+}
+
+// Explains why no texts are left to type after filtering them: which filters left out how many texts and how to relax them.
+func explainEmptyPool(report filterReport) {
+	fmt.Println("No texts are left to type")
+
+	if report.tooShort > 0 {
+		fmt.Printf("  %d %s shorter than -min-length %d: lower it\n", report.tooShort, pluralize("text", report.tooShort), *minLength)
+	}
+	if report.tooLong > 0 {
+		fmt.Printf("  %d %s longer than -max-length %d: raise it or leave it out\n", report.tooLong, pluralize("text", report.tooLong), *maxLength)
+	}
+	if report.untagged > 0 {
+		fmt.Printf("  %d %s without the tag %s: choose another -tag or leave it out\n", report.untagged, pluralize("text", report.untagged), *tag)
+	}
+	if report.empty+report.duplicates > 0 {
+		fmt.Printf("  %d empty or duplicate %s\n", report.empty+report.duplicates, pluralize("text", report.empty+report.duplicates))
+	}
+
+	if report.tooShort+report.tooLong+report.untagged == 0 {
+		fmt.Println("  There were no texts to begin with. Check the file given with -texts or the URL given with -url.")
+	}
+}
+
+// Prints how many texts are usable, how many were left out and why, and how long the usable texts are,
+// one "name: value" pair per line.
+func printCheck(texts []textEntry, report filterReport) {
+	fmt.Println("usable:", len(texts))
+	fmt.Println("empty:", report.empty)
+	fmt.Println("duplicate:", report.duplicates)
+	fmt.Println("too-short:", report.tooShort)
+	fmt.Println("too-long:", report.tooLong)
+	if *tag != "" {
+		fmt.Println("untagged:", report.untagged)
+	}
+
+	if len(texts) == 0 {
+		return
+	}
+
+	lengths := make([]int, len(texts))
+	total := 0
+	for i, entry := range texts {
+		lengths[i] = len([]rune(entry.text))
+		total += lengths[i]
+	}
+	sort.Ints(lengths)
+
+	fmt.Println("length-min:", lengths[0])
+	fmt.Println("length-median:", lengths[len(lengths)/2])
+	fmt.Printf("length-mean: %.1f\n", float64(total)/float64(len(lengths)))
+	fmt.Println("length-max:", lengths[len(lengths)-1])
+}
+
+// Reverses the characters of the text.
+// Combining marks such as accents stay after the character they belong to.
+func reverse(text string) string {
+	// Split the text into characters, each with the combining marks following it.
+	var chars [][]rune
+	for _, r := range text {
+		if len(chars) > 0 && unicode.Is(unicode.Mn, r) {
+			chars[len(chars)-1] = append(chars[len(chars)-1], r)
+		} else {
+			chars = append(chars, []rune{r})
+		}
+	}
+
+	var reversed strings.Builder
+	for i := len(chars) - 1; i >= 0; i-- {
+		reversed.WriteString(string(chars[i]))
+	}
+	return reversed.String()
+}
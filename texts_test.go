@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Checks that the lines of a plain texts file are taken as they are, even with words starting with # or dashes,
+// and that where the texts are from and their tags are only taken from a texts file in the JSON format.
+func TestLoadTexts(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "texts.txt")
+	if err := ioutil.WriteFile(plain, []byte("See issue #42\n  Tabs #and #spaces  \nI came — I saw -- I conquered\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tagged := filepath.Join(dir, "texts.json")
+	if err := ioutil.WriteFile(tagged, []byte(`[{"text": "A quote.", "source": "Someone", "url": "https://example.com", "tags": ["quotes", "beginner"]}, {"text": " See issue #42 "}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := loadTexts(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := plainTexts([]string{"See issue #42", "Tabs #and #spaces", "I came — I saw -- I conquered", ""}); !reflect.DeepEqual(entries, want) {
+		t.Errorf("plain texts: %+v, want %+v", entries, want)
+	}
+
+	entries, err = loadTexts(tagged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []textEntry{
+		{text: "A quote.", source: attribution{"Someone", "https://example.com"}, tags: []string{"quotes", "beginner"}},
+		{text: "See issue #42"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("texts in the JSON format: %+v, want %+v", entries, want)
+	}
+	if !entries[0].hasTag("beginner") || entries[1].hasTag("42") {
+		t.Errorf("texts in the JSON format have the wrong tags: %+v", entries)
+	}
+}
+
+// Checks that the texts left out by filterTexts are counted by why they were left out,
+// including when none are left, so that explainEmptyPool can say which filter to relax.
+func TestFilterTexts(t *testing.T) {
+	defer func(min, max int, tagged string) {
+		*minLength, *maxLength, *tag = min, max, tagged
+	}(*minLength, *maxLength, *tag)
+
+	pool := plainTexts([]string{"", "hi", "hello world", "hello world", "a tagged text", "", "a text much too long to type"})
+	pool[4].tags = []string{"quote"}
+
+	tests := []struct {
+		name     string
+		min, max int
+		tag      string
+		usable   int
+		report   filterReport
+	}{
+		{"unfiltered", 0, 0, "", 4, filterReport{empty: 2, duplicates: 1}},
+		{"within lengths", 5, 20, "", 2, filterReport{empty: 2, duplicates: 1, tooShort: 1, tooLong: 1}},
+		{"tagged", 0, 0, "quote", 1, filterReport{empty: 2, untagged: 4}},
+		{"too short", 100, 0, "", 0, filterReport{empty: 2, tooShort: 5}},
+		{"too long", 0, 1, "", 0, filterReport{empty: 2, tooLong: 5}},
+		{"overlapping lengths", 12, 11, "", 0, filterReport{empty: 2, tooShort: 3, tooLong: 2}},
+		{"missing tag", 5, 0, "poem", 0, filterReport{empty: 2, tooShort: 1, untagged: 4}},
+	}
+	for _, test := range tests {
+		*minLength, *maxLength, *tag = test.min, test.max, test.tag
+		filtered, report := filterTexts(pool)
+		if len(filtered) != test.usable || report != test.report {
+			t.Errorf("%s: %d usable, left out %+v, want %d usable, left out %+v", test.name, len(filtered), report, test.usable, test.report)
+		}
+	}
+
+	*minLength, *maxLength, *tag = 0, 0, ""
+	if filtered, report := filterTexts(nil); len(filtered) != 0 || report != (filterReport{}) {
+		t.Errorf("no texts: %d usable, left out %+v, want none", len(filtered), report)
+	}
+}
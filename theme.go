@@ -0,0 +1,125 @@
+package main
+
+import "strings"
+
+// What a color is used for.
+type colorRole int
+
+const (
+	// Characters typed right and a perfect score.
+	roleCorrect colorRole = iota
+	// Characters typed wrong and mistakes.
+	roleIncorrect
+	// The next character to type.
+	roleHighlight
+	// How a round went.
+	roleInfo
+	// The tokens of code highlighted with -code. See highlightLine.
+	roleKeyword
+	roleString
+	roleComment
+	roleNumber
+)
+
+// The color themes that can be chosen with -theme, mapping the roles to the escape sequences starting their colors.
+// A role without an escape sequence is left uncolored.
+// The default theme keeps to the colors typer always had.
+var themes = []struct {
+	name   string
+	colors map[colorRole]string
+}{
+	{"default", map[colorRole]string{
+		roleHighlight: inverseOn,
+		roleKeyword:   "\x1b[34m", // blue
+		roleString:    "\x1b[32m", // green
+		roleComment:   "\x1b[90m", // gray
+		roleNumber:    "\x1b[35m", // magenta
+	}},
+	{"high-contrast", map[colorRole]string{
+		roleCorrect:   "\x1b[1;97m",     // bold bright white
+		roleIncorrect: "\x1b[1;97;41m",  // bold bright white on red
+		roleHighlight: "\x1b[1;30;103m", // bold black on bright yellow
+		roleInfo:      "\x1b[1;96m",     // bold bright cyan
+		roleKeyword:   "\x1b[1;94m",     // bold bright blue
+		roleString:    "\x1b[1;92m",     // bold bright green
+		roleComment:   "\x1b[3;97m",     // italic bright white
+		roleNumber:    "\x1b[1;95m",     // bold bright magenta
+	}},
+	// Blue and orange can be told apart with the common kinds of color blindness, unlike red and green,
+	// and mistakes are underlined as well so that they never depend on color alone.
+	{"colorblind", map[colorRole]string{
+		roleCorrect:   "\x1b[34m",         // blue
+		roleIncorrect: "\x1b[4;38;5;208m", // underlined orange
+		roleHighlight: inverseOn,
+		roleInfo:      "\x1b[36m",       // cyan
+		roleKeyword:   "\x1b[34m",       // blue
+		roleString:    "\x1b[38;5;208m", // orange
+		roleComment:   "\x1b[90m",       // gray
+		roleNumber:    "\x1b[36m",       // cyan
+	}},
+	{"monochrome", map[colorRole]string{
+		roleIncorrect: "\x1b[4m", // underlined
+		roleHighlight: inverseOn,
+		roleInfo:      "\x1b[1m", // bold
+		roleKeyword:   "\x1b[1m", // bold
+		roleComment:   "\x1b[2m", // dim
+	}},
+}
+
+// Describes the themes for the usage message.
+func describeThemes() string {
+	var names []string
+	for _, theme := range themes {
+		names = append(names, theme.name)
+	}
+	return "the colors to use: " + strings.Join(names, ", ")
+}
+
+// Gets the colors of the theme, which are nil if there is no such theme.
+func themeColors(name string) map[colorRole]string {
+	for _, theme := range themes {
+		if theme.name == name {
+			return theme.colors
+		}
+	}
+	return nil
+}
+
+// Colors the string as the -theme colors the role.
+// If colors are disabled, only the next character to type is still shown, with its colors inverted.
+func paint(role colorRole, s string) string {
+	color := themeColors(*theme)[role]
+	if !colorEnabled() {
+		if role != roleHighlight {
+			return s
+		}
+		color = inverseOn
+	}
+
+	if color == "" || s == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// Colors each character of the input by whether it is the character of the text in its place.
+func paintInput(input, text []rune) string {
+	var painted strings.Builder
+	start := 0
+	for i := range input {
+		if i+1 < len(input) && inputRole(input, text, i+1) == inputRole(input, text, i) {
+			continue
+		}
+		painted.WriteString(paint(inputRole(input, text, i), string(input[start:i+1])))
+		start = i + 1
+	}
+	return painted.String()
+}
+
+// Gets the role of the character of the input at the index.
+func inputRole(input, text []rune, i int) colorRole {
+	if i < len(text) && input[i] == text[i] {
+		return roleCorrect
+	}
+	return roleIncorrect
+}
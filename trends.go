@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// How many of the most recent days and weeks the trends of the stats cover.
+const (
+	trendDays  = 14
+	trendWeeks = 8
+)
+
+// The attempts made during a day or week, taken together.
+type period struct {
+	// The name of the period, such as "2006-01-02" for a day or "2006-W01" for a week.
+	name     string
+	attempts int
+	wpm      float64
+	accuracy float64
+}
+
+// Prints the period as a row of a table.
+func (p period) print() {
+	fmt.Printf("  %-10s %4d %-8s %6.1f WPM %6.1f%%\n", p.name, p.attempts, pluralize("attempt", p.attempts), p.wpm, p.accuracy)
+}
+
+// Groups the attempts at all texts into periods by the name the time of each attempt gets,
+// keeping the most recent periods up to the limit, oldest first. Periods without any attempts are left out.
+func groupAttempts(name func(time.Time) string, limit int) []period {
+	periods := make(map[string]*period)
+	for _, record := range scores.Records {
+		for _, attempt := range record.Attempts {
+			if attempt.Distractor {
+				continue
+			}
+			key := name(attempt.Time.Local())
+			if periods[key] == nil {
+				periods[key] = &period{name: key}
+			}
+			periods[key].attempts++
+			periods[key].wpm += attempt.WPM
+			periods[key].accuracy += attempt.Accuracy
+		}
+	}
+
+	var grouped []period
+	for _, p := range periods {
+		p.wpm /= float64(p.attempts)
+		p.accuracy /= float64(p.attempts)
+		grouped = append(grouped, *p)
+	}
+	// The names sort in time order.
+	sort.Slice(grouped, func(i, j int) bool {
+		return grouped[i].name < grouped[j].name
+	})
+	if len(grouped) > limit {
+		grouped = grouped[len(grouped)-limit:]
+	}
+	return grouped
+}
+
+// Gets the name of the day of the time.
+func dayName(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// Gets the name of the ISO week of the time.
+func weekName(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// Prints the average speed and accuracy of each of the most recent days and weeks practiced,
+// to see how they develop over time, and on how many of the most recent days there was practice.
+func printTrends() {
+	days := groupAttempts(dayName, trendDays)
+	if len(days) == 0 {
+		return
+	}
+
+	recent := 0
+	since := dayName(now().AddDate(0, 0, -(trendDays - 1)))
+	for _, day := range days {
+		if day.name >= since {
+			recent++
+		}
+	}
+	fmt.Printf("Practiced on %d of the last %d days\n", recent, trendDays)
+
+	printPeriods("By day:", days)
+	printPeriods("By week:", groupAttempts(weekName, trendWeeks))
+}
+
+// Prints a table of the periods and, if there are enough of them, the trend of their speed as a sparkline.
+func printPeriods(title string, periods []period) {
+	fmt.Println(title)
+	for _, p := range periods {
+		p.print()
+	}
+
+	if len(periods) < minSparklineLength {
+		return
+	}
+	values := make([]float64, len(periods))
+	lowest, highest := periods[0].wpm, periods[0].wpm
+	for i, p := range periods {
+		values[i] = p.wpm
+		if p.wpm < lowest {
+			lowest = p.wpm
+		}
+		if p.wpm > highest {
+			highest = p.wpm
+		}
+	}
+	fmt.Printf("  Speed trend: %s (%.1f to %.1f WPM)\n", sparkline(values, lowest, highest), values[0], values[len(values)-1])
+}
+
+// The fewest attempts made at an hour of the day for it to be named as the one typed fastest at.
+const minHourAttempts = 3
+
+// Gets the name of the hour of the day of the time.
+func hourName(t time.Time) string {
+	return t.Format("15:00")
+}
+
+// Prints the average speed and accuracy at each hour of the day in local time that was practiced at,
+// and the hour typed fastest at out of those with enough attempts to tell.
+func printHourStats() {
+	hours := groupAttempts(hourName, 24)
+	if len(hours) == 0 {
+		return
+	}
+
+	fmt.Println("By hour of day:")
+	var fastest *period
+	for i, hour := range hours {
+		hour.print()
+		if hour.attempts >= minHourAttempts && (fastest == nil || hour.wpm > fastest.wpm) {
+			fastest = &hours[i]
+		}
+	}
+
+	if fastest != nil && len(hours) > 1 {
+		fmt.Printf("  You type fastest around %s, at %.1f WPM on average\n", fastest.name, fastest.wpm)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// How long fetching the texts from -url may take.
+const urlTimeout = 15 * time.Second
+
+// The most bytes read from -url, which is plenty for texts to type.
+const maxURLSize = 10 << 20
+
+// Loads the texts to be typed from prose at an HTTP(S) URL, split into sentences.
+// What was fetched is cached in the configuration directory, so that if fetching fails later, such as when offline,
+// the cached copy is used instead.
+func loadURL(url string) ([]textEntry, error) {
+	cachePath := urlCachePath(url)
+
+	content, err := fetchText(url)
+	if err != nil {
+		cached, cacheErr := ioutil.ReadFile(cachePath)
+		if cacheErr != nil {
+			return nil, err
+		}
+
+		fmt.Println("Failed to fetch the texts:", err)
+		if info, statErr := os.Stat(cachePath); statErr == nil {
+			fmt.Println("Using the copy fetched on", info.ModTime().Format("2006-01-02 15:04"))
+		}
+		content = cached
+	} else if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+		// Without a cache, only working offline fails.
+		ioutil.WriteFile(cachePath, content, 0644)
+	}
+
+	return plainTexts(splitSentences(string(content))), nil
+}
+
+// Gets the file the text fetched from the URL is cached in.
+func urlCachePath(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return filepath.Join(configDir(), "urls", hex.EncodeToString(hash[:])+".txt")
+}
+
+// Fetches the text at the URL, failing if it is not served as text other than HTML.
+func fetchText(url string) ([]byte, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("only http:// and https:// URLs are supported")
+	}
+
+	client := http.Client{Timeout: urlTimeout}
+	response, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the server answered %s", response.Status)
+	}
+
+	if contentType := response.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !strings.HasPrefix(mediaType, "text/") || mediaType == "text/html" {
+			return nil, fmt.Errorf("the URL points to %s rather than text", contentType)
+		}
+	}
+
+	return ioutil.ReadAll(io.LimitReader(response.Body, maxURLSize))
+}
@@ -0,0 +1,52 @@
+package main
+
+import "strings"
+
+// Common English words used to generate texts.
+var words = strings.Fields(`
+	the be to of and a in that have it for not on with he as you do at this but his by from
+	they we say her she or an will my one all would there their what so up out if about who
+	get which go me when make can like time no just him know take people into year your good
+	some could them see other than then now look only come its over think also back after use
+	two how our work first well way even new want because any these give day most us is was
+	are been has had were said did very much where right through world still own while last
+	might great old big high small large next early young important few public bad same able
+	life child hand part place case week company system program question government number night
+	point home water room mother area money story fact month lot study book eye job word business
+	issue side kind head house service friend father power hour game line end member law car city
+	community name president team minute idea kid body information school face others level office
+	door health person art war history party result change morning reason research girl guy moment
+	air teacher force education foot boy age policy music market sense nation plan college interest
+	death experience effect class control care field development role effort rate heart drug show
+	leader light voice wife police mind price report decision son view relationship town road arm
+	quick brown fox jumps lazy dog quiet zone jazz quiz box fix mix wax oxygen zebra query
+`)
+
+// How many words a generated text consists of.
+const wordsPerText = 8
+
+// Generates a text of random words.
+// With the given characters, words containing them are preferred,
+// the more so the more of them they contain.
+func generateText(focus []rune) string {
+	var preferred []string
+	for _, word := range words {
+		for _, char := range word {
+			if strings.ContainsRune(string(focus), char) {
+				preferred = append(preferred, word)
+			}
+		}
+	}
+
+	generated := make([]string, wordsPerText)
+	for i := range generated {
+		// Mix in some other words so that the text does not get too repetitive.
+		if len(preferred) > 0 && rng.Intn(4) != 0 {
+			generated[i] = preferred[rng.Intn(len(preferred))]
+		} else {
+			generated[i] = words[rng.Intn(len(words))]
+		}
+	}
+
+	return strings.Join(generated, " ")
+}